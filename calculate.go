@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/rs/zerolog"
-	"golang.org/x/mod/semver"
 )
 
 type Calculation struct {
@@ -17,6 +16,12 @@ type Calculation struct {
 	IsMerged          bool
 	ForcePatch        bool
 	Skip              bool
+	Channel           string     // prerelease channel of the branch, e.g. rc
+	Promote           bool       // MyMostRecentTag is a channel version that is being promoted
+	PushBuild         int        // build number for the build tag of a push (or of a skipped release), 0 if there is none
+	SkipReason        string     // why the release was skipped, empty if it was not skipped by a directive
+	Scheme            Scheme     `json:"-"` // how versions advance, semver if nil
+	TagFormat         *TagFormat `json:"-"` // how new pr, base and reserved tags are named, the default format if nil
 }
 
 type CalculationOutput struct {
@@ -54,7 +59,7 @@ func (out *CalculationOutput) ApplyRefs(opts RefProvider) Tags {
 	return tags
 }
 
-func (me *Calculation) CalculateNewTagsRaw(ctx context.Context) *CalculationOutput {
+func (me *Calculation) CalculateNewTagsRaw(ctx context.Context) (*CalculationOutput, error) {
 	out := &CalculationOutput{
 		BaseTags:  []string{},
 		HeadTags:  []string{},
@@ -64,18 +69,37 @@ func (me *Calculation) CalculateNewTagsRaw(ctx context.Context) *CalculationOutp
 
 	if me.Skip {
 		zerolog.Ctx(ctx).Debug().Any("calculation", me).Msg("Skipping calculation")
-		return out
+
+		// a skipped release still tags the commit, with a build of the current version
+		if me.SkipReason != "" && me.PushBuild > 0 {
+			// without a live tag, this is a build of v0.0.0
+			marker := tagFormatOrDefault(me.TagFormat).BuildTag(me.MostRecentLiveTag.String(), me.PushBuild)
+			if me.IsMerged {
+				out.MergeTags = append(out.MergeTags, marker)
+			} else {
+				out.HeadTags = append(out.HeadTags, marker)
+			}
+		}
+
+		return out, nil
 	}
 
-	nvt := string(me.NextValidTag)
+	nvt := me.NextValidTag
 
-	mmrt := string(me.MyMostRecentTag)
+	mmrt := me.MyMostRecentTag
 
-	mrlt := string(me.MostRecentLiveTag)
+	mrlt := me.MostRecentLiveTag
 
 	// first we check to see if mrlt exists, if not we set it to the base
-	if mrlt == "" {
-		mrlt = baseTag
+	if mrlt.IsZero() {
+		mrlt = baseVersion
+	}
+
+	format := tagFormatOrDefault(me.TagFormat)
+
+	if me.Channel != "" {
+		me.calculateChannelTags(ctx, format, out, mmrt, mrlt, nvt)
+		return out, nil
 	}
 
 	// mmrt and mrlt will always be the same on the first pr build
@@ -84,54 +108,94 @@ func (me *Calculation) CalculateNewTagsRaw(ctx context.Context) *CalculationOutp
 	validMmrt := false
 
 	// first we validate that mmrt is still valid, which means it is greater than or equal to mrlt
-	if mmrt != "" && semver.Compare(mmrt, mrlt) > 0 {
+	if !mmrt.IsZero() && mmrt.Compare(mrlt) > 0 {
 		validMmrt = true
 	}
 
-	if mmrt != "" && semver.Compare(mmrt, mrlt) == 0 && me.MyMostRecentBuild != 0 {
+	if !mmrt.IsZero() && mmrt.Compare(mrlt) == 0 && me.MyMostRecentBuild != 0 {
 		validMmrt = false
-		nvt = BumpPatch(mmrt)
+		nvt = me.patch(mmrt)
 	} else if !me.IsMerged {
 		if me.MyMostRecentBuild == 0 {
 			validMmrt = false
 		} else if me.ForcePatch {
-			nvt = BumpPatch(mmrt)
+			nvt = me.patch(mmrt)
 			validMmrt = false
 		}
 	}
 
+	// a promoted channel version was reserved when the channel started, so it can be used as is
+	if me.Promote && !mmrt.IsZero() && mmrt.Compare(mrlt) > 0 {
+		validMmrt = true
+	}
+
 	// if mmrt is invalid, then we need to reserve a new mmrt (which is the same as nvt)
 	if !validMmrt {
 		mmrt = nvt
 		// pr will be 0 if this is not merged and is a push to the root branch
 		if me.PR != 0 && !me.IsMerged {
-			out.RootTags = append(out.RootTags, mmrt+"-reserved")
-			out.BaseTags = append(out.BaseTags, mmrt+fmt.Sprintf("-pr%d+base", me.PR))
+			out.RootTags = append(out.RootTags, format.ReservedTag(mmrt.String()))
+			out.BaseTags = append(out.BaseTags, format.BaseTag(mmrt.String(), me.PR))
 		}
 	}
 
 	if me.IsMerged {
 		// if !matching {
-		out.MergeTags = append(out.MergeTags, mmrt)
+		out.MergeTags = append(out.MergeTags, mmrt.String())
 		// }
 	} else {
 		if me.PR == 0 {
-			out.HeadTags = append(out.HeadTags, mmrt)
+			out.HeadTags = append(out.HeadTags, mmrt.String())
+			if me.PushBuild > 0 {
+				out.HeadTags = append(out.HeadTags, format.BuildTag(mmrt.String(), me.PushBuild))
+			}
 		} else {
-			out.HeadTags = append(out.HeadTags, mmrt+fmt.Sprintf("-pr%d+%d", me.PR, int(me.MyMostRecentBuild)+1))
+			out.HeadTags = append(out.HeadTags, format.PRTag(mmrt.String(), me.PR, int(me.MyMostRecentBuild)+1))
 		}
 	}
 
 	zerolog.Ctx(ctx).Debug().
 		Any("calculation", me).
 		Any("output", out).
-		Str("mmrt", mmrt).
-		Str("mrlt", mrlt).
-		Str("nvt", nvt).
+		Stringer("mmrt", mmrt).
+		Stringer("mrlt", mrlt).
+		Stringer("nvt", nvt).
 		Str("pr", fmt.Sprintf("%d", me.PR)).
 		Bool("isMerge", me.IsMerged).
 		Bool("forcePatch", me.ForcePatch).
 		Msg("CalculateNewTagsRaw")
 
-	return out
+	return out, nil
+}
+
+// patch returns the version right after mmrt, according to the scheme
+func (me *Calculation) patch(mmrt MMRT) NVT {
+	return schemeOrDefault(me.Scheme).Patch(mmrt)
+}
+
+func (me *Calculation) calculateChannelTags(ctx context.Context, format *TagFormat, out *CalculationOutput, mmrt MMRT, mrlt MRLT, nvt NVT) {
+	build := int(me.MyMostRecentBuild)
+
+	// mmrt is the channel version, it stays valid until it is released
+	if mmrt.IsZero() || mmrt.Compare(mrlt) <= 0 {
+		mmrt = nvt
+		build = 0
+		out.RootTags = append(out.RootTags, format.ReservedTag(mmrt.String()))
+	}
+
+	tag := format.ChannelTag(mmrt.String(), me.Channel, build+1)
+
+	if me.IsMerged {
+		out.MergeTags = append(out.MergeTags, tag)
+	} else {
+		out.HeadTags = append(out.HeadTags, tag)
+	}
+
+	zerolog.Ctx(ctx).Debug().
+		Any("calculation", me).
+		Any("output", out).
+		Stringer("mmrt", mmrt).
+		Stringer("mrlt", mrlt).
+		Str("channel", me.Channel).
+		Msg("CalculateNewTagsRaw channel")
 }
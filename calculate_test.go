@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/walteh/simver"
 )
 
@@ -17,11 +18,11 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 		{
 			name: "expired mmrt",
 			calculation: &simver.Calculation{
-				MostRecentLiveTag: "v1.10.3",
-				MyMostRecentTag:   "v1.9.9",
+				MostRecentLiveTag: ver("v1.10.3"),
+				MyMostRecentTag:   ver("v1.9.9"),
 				MyMostRecentBuild: 33,
 				PR:                85,
-				NextValidTag:      "v99.99.99",
+				NextValidTag:      ver("v99.99.99"),
 				IsMerged:          false,
 				ForcePatch:        false,
 			},
@@ -41,11 +42,11 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 		{
 			name: "missing all",
 			calculation: &simver.Calculation{
-				MostRecentLiveTag: "",
-				MyMostRecentTag:   "",
+				MostRecentLiveTag: ver(""),
+				MyMostRecentTag:   ver(""),
 				MyMostRecentBuild: 1,
 				PR:                1,
-				NextValidTag:      "v3.3.3",
+				NextValidTag:      ver("v3.3.3"),
 				IsMerged:          false,
 				ForcePatch:        false,
 			},
@@ -66,11 +67,11 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 		{
 			name: "valid mmrt",
 			calculation: &simver.Calculation{
-				MostRecentLiveTag: "v1.2.3",
-				MyMostRecentTag:   "v1.2.4",
+				MostRecentLiveTag: ver("v1.2.3"),
+				MyMostRecentTag:   ver("v1.2.4"),
 				MyMostRecentBuild: 33,
 				PR:                1,
-				NextValidTag:      "v1.2.6",
+				NextValidTag:      ver("v1.2.6"),
 				IsMerged:          false,
 				ForcePatch:        false,
 			},
@@ -86,11 +87,11 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 		{
 			name: "i have a tag reserved but do not have my own tag",
 			calculation: &simver.Calculation{
-				MostRecentLiveTag: "v1.2.3",
-				MyMostRecentTag:   "",
+				MostRecentLiveTag: ver("v1.2.3"),
+				MyMostRecentTag:   ver(""),
 				MyMostRecentBuild: 33,
 				PR:                1,
-				NextValidTag:      "v1.2.6",
+				NextValidTag:      ver("v1.2.6"),
 				IsMerged:          false,
 				ForcePatch:        false,
 			},
@@ -105,11 +106,11 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 		{
 			name: "valid mmrt with merge",
 			calculation: &simver.Calculation{
-				MostRecentLiveTag: "v1.2.3",
-				MyMostRecentTag:   "v1.2.4",
+				MostRecentLiveTag: ver("v1.2.3"),
+				MyMostRecentTag:   ver("v1.2.4"),
 				MyMostRecentBuild: 33,
 				PR:                1,
-				NextValidTag:      "v1.2.6",
+				NextValidTag:      ver("v1.2.6"),
 				IsMerged:          true,
 				ForcePatch:        false,
 			},
@@ -123,11 +124,11 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 		{
 			name: "valid mmrt with force patch",
 			calculation: &simver.Calculation{
-				MostRecentLiveTag: "v1.2.3",
-				MyMostRecentTag:   "v1.2.4",
+				MostRecentLiveTag: ver("v1.2.3"),
+				MyMostRecentTag:   ver("v1.2.4"),
 				MyMostRecentBuild: 33,
 				PR:                1,
-				NextValidTag:      "v1.2.6",
+				NextValidTag:      ver("v1.2.6"),
 				IsMerged:          false,
 				ForcePatch:        true,
 			},
@@ -141,11 +142,11 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 		{
 			name: "valid mmrt with force patch (merge override)",
 			calculation: &simver.Calculation{
-				MostRecentLiveTag: "v1.2.3",
-				MyMostRecentTag:   "v1.2.4",
+				MostRecentLiveTag: ver("v1.2.3"),
+				MyMostRecentTag:   ver("v1.2.4"),
 				MyMostRecentBuild: 33,
 				PR:                1,
-				NextValidTag:      "v1.2.6",
+				NextValidTag:      ver("v1.2.6"),
 				IsMerged:          true,
 				ForcePatch:        true,
 			},
@@ -159,11 +160,11 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 		{
 			name: "expired mmrt with force patch",
 			calculation: &simver.Calculation{
-				MostRecentLiveTag: "v1.10.3",
-				MyMostRecentTag:   "v1.9.9",
+				MostRecentLiveTag: ver("v1.10.3"),
+				MyMostRecentTag:   ver("v1.9.9"),
 				MyMostRecentBuild: 33,
 				PR:                85,
-				NextValidTag:      "v99.99.99",
+				NextValidTag:      ver("v99.99.99"),
 				IsMerged:          false,
 				ForcePatch:        true,
 			},
@@ -180,10 +181,10 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 			calculation: &simver.Calculation{
 				ForcePatch:        false,
 				IsMerged:          false,
-				MostRecentLiveTag: "v0.17.2",
+				MostRecentLiveTag: ver("v0.17.2"),
 				MyMostRecentBuild: 1.000000,
-				MyMostRecentTag:   "v0.17.3",
-				NextValidTag:      "v0.18.0",
+				MyMostRecentTag:   ver("v0.17.3"),
+				NextValidTag:      ver("v0.18.0"),
 				PR:                13.000000,
 			},
 			output: &simver.CalculationOutput{
@@ -198,10 +199,10 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 			calculation: &simver.Calculation{
 				ForcePatch:        false,
 				IsMerged:          true,
-				MostRecentLiveTag: "v0.3.0",
+				MostRecentLiveTag: ver("v0.3.0"),
 				MyMostRecentBuild: 1.000000,
-				MyMostRecentTag:   "v0.3.0",
-				NextValidTag:      "v0.4.0",
+				MyMostRecentTag:   ver("v0.3.0"),
+				NextValidTag:      ver("v0.4.0"),
 				PR:                1.000000,
 			},
 			output: &simver.CalculationOutput{
@@ -216,10 +217,10 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 			calculation: &simver.Calculation{
 				ForcePatch:        true,
 				IsMerged:          true,
-				MostRecentLiveTag: "v0.2.0",
+				MostRecentLiveTag: ver("v0.2.0"),
 				MyMostRecentBuild: 1.000000,
-				MyMostRecentTag:   "v0.2.0",
-				NextValidTag:      "v0.3.0",
+				MyMostRecentTag:   ver("v0.2.0"),
+				NextValidTag:      ver("v0.3.0"),
 				PR:                1.000000,
 			},
 			output: &simver.CalculationOutput{
@@ -234,10 +235,10 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 			calculation: &simver.Calculation{
 				ForcePatch:        true,
 				IsMerged:          true,
-				MostRecentLiveTag: "v0.2.0",
+				MostRecentLiveTag: ver("v0.2.0"),
 				MyMostRecentBuild: 0,
-				MyMostRecentTag:   "v0.2.0",
-				NextValidTag:      "v0.3.0",
+				MyMostRecentTag:   ver("v0.2.0"),
+				NextValidTag:      ver("v0.3.0"),
 				PR:                1.000000,
 			},
 			output: &simver.CalculationOutput{
@@ -254,10 +255,10 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 
 				ForcePatch:        true,
 				IsMerged:          false,
-				MostRecentLiveTag: "v0.4.1",
+				MostRecentLiveTag: ver("v0.4.1"),
 				MyMostRecentBuild: 0.000000,
-				MyMostRecentTag:   "v0.4.1",
-				NextValidTag:      "v0.5.0",
+				MyMostRecentTag:   ver("v0.4.1"),
+				NextValidTag:      ver("v0.5.0"),
 				PR:                3.000000,
 			},
 			output: &simver.CalculationOutput{
@@ -273,10 +274,10 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 			calculation: &simver.Calculation{
 				ForcePatch:        false,
 				IsMerged:          true,
-				MostRecentLiveTag: "v0.4.2",
+				MostRecentLiveTag: ver("v0.4.2"),
 				MyMostRecentBuild: 3.000000,
-				MyMostRecentTag:   "v0.4.2",
-				NextValidTag:      "v0.5.0",
+				MyMostRecentTag:   ver("v0.4.2"),
+				NextValidTag:      ver("v0.5.0"),
 				PR:                3.000000,
 				Skip:              true,
 			},
@@ -292,10 +293,10 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 			calculation: &simver.Calculation{
 				ForcePatch:        false,
 				IsMerged:          true,
-				MostRecentLiveTag: "v0.18.0",
+				MostRecentLiveTag: ver("v0.18.0"),
 				MyMostRecentBuild: 0.000000,
-				MyMostRecentTag:   "v0.18.1",
-				NextValidTag:      "v0.19.0",
+				MyMostRecentTag:   ver("v0.18.1"),
+				NextValidTag:      ver("v0.19.0"),
 				PR:                14.000000,
 				Skip:              false,
 			},
@@ -312,10 +313,10 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 			calculation: &simver.Calculation{
 				ForcePatch:        false,
 				IsMerged:          true,
-				MostRecentLiveTag: "",
+				MostRecentLiveTag: ver(""),
 				MyMostRecentBuild: 0.000000,
-				MyMostRecentTag:   "",
-				NextValidTag:      "v0.3.0",
+				MyMostRecentTag:   ver(""),
+				NextValidTag:      ver("v0.3.0"),
 				PR:                1.000000,
 				Skip:              false,
 			},
@@ -332,7 +333,8 @@ func TestNewCalculationAndCalculateNewTags(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			out := tc.calculation.CalculateNewTagsRaw(ctx)
+			out, err := tc.calculation.CalculateNewTagsRaw(ctx)
+			require.NoError(t, err)
 			assert.ElementsMatch(t, tc.output.BaseTags, out.BaseTags, "base tags do not match")
 			assert.ElementsMatch(t, tc.output.HeadTags, out.HeadTags, "head tags do not match")
 			assert.ElementsMatch(t, tc.output.RootTags, out.RootTags, "root tags do not match")
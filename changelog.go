@@ -0,0 +1,272 @@
+package simver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"gitlab.com/tozd/go/errors"
+)
+
+// ChangelogGroupConfig is a section of the changelog. Entries land in the first group that matches one of
+// their pr labels, then in the first group that matches their conventional commit type.
+type ChangelogGroupConfig struct {
+	Title  string   `yaml:"title"`
+	Types  []string `yaml:"types"`  // conventional commit types, e.g. feat
+	Labels []string `yaml:"labels"` // pr labels, e.g. enhancement
+}
+
+type ChangelogConfig struct {
+	Groups []*ChangelogGroupConfig `yaml:"groups"`
+}
+
+var DefaultChangelogGroups = []*ChangelogGroupConfig{
+	{Title: "Features", Types: []string{"feat"}, Labels: []string{"enhancement", "feature"}},
+	{Title: "Bug Fixes", Types: []string{"fix"}, Labels: []string{"bug"}},
+	{Title: "Performance", Types: []string{"perf"}, Labels: []string{"performance"}},
+	{Title: "Documentation", Types: []string{"docs"}, Labels: []string{"documentation"}},
+}
+
+const (
+	breakingChangesTitle = "Breaking Changes"
+	otherChangesTitle    = "Other Changes"
+)
+
+type ChangelogEntry struct {
+	Summary  string   `json:"summary"`
+	Type     string   `json:"type,omitempty"`  // conventional commit type, e.g. feat
+	Scope    string   `json:"scope,omitempty"` // conventional commit scope, e.g. cli
+	Breaking bool     `json:"breaking,omitempty"`
+	PR       int      `json:"pr,omitempty"`
+	Commit   string   `json:"commit"`
+	Labels   []string `json:"labels,omitempty"`
+}
+
+type ChangelogSection struct {
+	Title   string            `json:"title"`
+	Entries []*ChangelogEntry `json:"entries"`
+}
+
+type Changelog struct {
+	Version  string              `json:"version"`
+	Previous string              `json:"previous,omitempty"`
+	Sections []*ChangelogSection `json:"sections"`
+}
+
+var conventionalCommitRegex = regexp.MustCompile(`^(\w+)(?:\(([^)]+)\))?(!)?:\s*(.+)$`)
+
+// prSubjectRegex finds the pr of a merge commit ("Merge pull request #7 from ...") or a squashed one ("title (#7)")
+var prSubjectRegex = regexp.MustCompile(`^Merge pull request #(\d+) |\(#(\d+)\)$`)
+
+// MaxChangelogCommits is how many commits a changelog looks at, so a release without a previous one
+// doesn't walk the whole history
+const MaxChangelogCommits = 500
+
+// prFromSubject returns the pr number in the subject of a merge commit, if there is one
+func prFromSubject(subject string) (int, bool) {
+	m := prSubjectRegex.FindStringSubmatch(strings.TrimSpace(subject))
+	if m == nil {
+		return 0, false
+	}
+	num, err := strconv.Atoi(m[1] + m[2])
+	if err != nil {
+		return 0, false
+	}
+	return num, true
+}
+
+// parseConventional splits a conventional commit subject (like "feat(cli)!: add flag") into its parts.
+// Subjects that are not conventional are returned as the summary.
+func parseConventional(subject, body string) *ChangelogEntry {
+	entry := &ChangelogEntry{Summary: strings.TrimSpace(subject)}
+
+	if m := conventionalCommitRegex.FindStringSubmatch(entry.Summary); m != nil {
+		entry.Type = strings.ToLower(m[1])
+		entry.Scope = m[2]
+		entry.Breaking = m[3] == "!"
+		entry.Summary = m[4]
+	}
+
+	if strings.Contains(body, "BREAKING CHANGE:") || strings.Contains(body, "BREAKING-CHANGE:") {
+		entry.Breaking = true
+	}
+
+	return entry
+}
+
+// BuildChangelog collects the changes between two versions, at most MaxChangelogCommits of them. Merge commits
+// and squashed commits that name a merged pr in their subject are described by the pr, which is only looked
+// up if prp is not nil.
+func BuildChangelog(ctx context.Context, gp GitProvider, prp PRProvider, cfg *Config, previous, version string) (*Changelog, error) {
+	commits, err := gp.Commits(ctx, previous, version)
+	if err != nil {
+		return nil, errors.Errorf("listing commits between %q and %q: %w", previous, version, err)
+	}
+
+	if len(commits) > MaxChangelogCommits {
+		zerolog.Ctx(ctx).Warn().Int("commits", len(commits)).Int("limit", MaxChangelogCommits).Msg("too many commits for the changelog, only the most recent ones are listed")
+		commits = commits[:MaxChangelogCommits]
+	}
+
+	entries := make([]*ChangelogEntry, 0, len(commits))
+	seen := map[int]bool{}
+
+	for _, c := range commits {
+		var pr *PRDetails
+		if num, ok := prFromSubject(c.Subject); ok && prp != nil {
+			if seen[num] {
+				continue
+			}
+
+			dets, found, err := prp.PRDetailsByPRNumber(ctx, num)
+			if err != nil {
+				return nil, errors.Errorf("finding pr #%d of commit %s: %w", num, c.Hash, err)
+			}
+			if found && dets.Merged {
+				pr = dets
+			}
+		}
+
+		var entry *ChangelogEntry
+		if pr != nil {
+			seen[pr.Number] = true
+
+			title := pr.Title
+			if title == "" {
+				title = c.Subject
+			}
+
+			entry = parseConventional(title, c.Body)
+			entry.PR = pr.Number
+			entry.Labels = pr.Labels
+		} else {
+			entry = parseConventional(c.Subject, c.Body)
+		}
+
+		entry.Commit = c.Hash
+		entries = append(entries, entry)
+	}
+
+	zerolog.Ctx(ctx).Debug().Int("commits", len(commits)).Int("entries", len(entries)).Msg("built changelog")
+
+	return &Changelog{
+		Version:  version,
+		Previous: previous,
+		Sections: groupChangelog(entries, cfg.ChangelogGroups()),
+	}, nil
+}
+
+func groupChangelog(entries []*ChangelogEntry, groups []*ChangelogGroupConfig) []*ChangelogSection {
+	sections := map[string]*ChangelogSection{}
+	order := []string{breakingChangesTitle}
+	for _, g := range groups {
+		order = append(order, g.Title)
+	}
+	order = append(order, otherChangesTitle)
+
+	add := func(title string, entry *ChangelogEntry) {
+		if sections[title] == nil {
+			sections[title] = &ChangelogSection{Title: title}
+		}
+		sections[title].Entries = append(sections[title].Entries, entry)
+	}
+
+	for _, entry := range entries {
+		if entry.Breaking {
+			add(breakingChangesTitle, entry)
+			continue
+		}
+
+		add(groupFor(entry, groups), entry)
+	}
+
+	out := make([]*ChangelogSection, 0, len(sections))
+	for _, title := range order {
+		if s, ok := sections[title]; ok {
+			out = append(out, s)
+		}
+	}
+
+	return out
+}
+
+func groupFor(entry *ChangelogEntry, groups []*ChangelogGroupConfig) string {
+	for _, g := range groups {
+		if slices.ContainsFunc(entry.Labels, func(l string) bool { return slices.Contains(g.Labels, l) }) {
+			return g.Title
+		}
+	}
+
+	for _, g := range groups {
+		if entry.Type != "" && slices.Contains(g.Types, entry.Type) {
+			return g.Title
+		}
+	}
+
+	return otherChangesTitle
+}
+
+// Markdown renders the changelog as release notes
+func (me *Changelog) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## %s\n", me.Version)
+
+	if len(me.Sections) == 0 {
+		b.WriteString("\nNo changes.\n")
+	}
+
+	for _, s := range me.Sections {
+		fmt.Fprintf(&b, "\n### %s\n\n", s.Title)
+
+		for _, e := range s.Entries {
+			b.WriteString("- ")
+			if e.Scope != "" {
+				fmt.Fprintf(&b, "**%s:** ", e.Scope)
+			}
+			b.WriteString(e.Summary)
+			if e.PR != 0 {
+				fmt.Fprintf(&b, " (#%d)", e.PR)
+			} else if len(e.Commit) >= 7 {
+				fmt.Fprintf(&b, " (%s)", e.Commit[:7])
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if me.Previous != "" {
+		fmt.Fprintf(&b, "\n**Full Changelog**: %s...%s\n", me.Previous, me.Version)
+	}
+
+	return b.String()
+}
+
+func (me *Changelog) JSON() (string, error) {
+	byt, err := json.MarshalIndent(me, "", "  ")
+	if err != nil {
+		return "", errors.Errorf("marshalling changelog: %w", err)
+	}
+	return string(byt), nil
+}
+
+// PreviousRelease returns the highest release before version with the same module prefix, or "" if there is none
+func PreviousRelease(tags Tags, version string) string {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return ""
+	}
+
+	prev, ok := tags.Versions().WithPrefix(v.Prefix).WithRole(TagRoleRelease).Filter(func(o Version) bool {
+		return o.Compare(v) < 0
+	}).Highest()
+	if !ok {
+		return ""
+	}
+
+	return prev.String()
+}
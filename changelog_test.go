@@ -0,0 +1,181 @@
+package simver_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+)
+
+type fakeCommits struct {
+	simver.GitProvider
+	commits []simver.Commit
+}
+
+func (me *fakeCommits) Commits(_ context.Context, _, _ string) ([]simver.Commit, error) {
+	return me.commits, nil
+}
+
+type fakePRs struct {
+	simver.PRProvider
+	byNumber map[int]*simver.PRDetails
+	lookups  int
+}
+
+func (me *fakePRs) PRDetailsByPRNumber(_ context.Context, number int) (*simver.PRDetails, bool, error) {
+	me.lookups++
+	pr, ok := me.byNumber[number]
+	return pr, ok, nil
+}
+
+func TestBuildChangelog(t *testing.T) {
+	ctx := context.Background()
+
+	gp := &fakeCommits{commits: []simver.Commit{
+		{Hash: "aaaaaaaaaa", Subject: "feat(cli): add changelog command"},
+		{Hash: "bbbbbbbbbb", Subject: "fix: handle empty tags (#8)"},
+		{Hash: "cccccccccc", Subject: "refactor!: drop old flags"},
+		{Hash: "dddddddddd", Subject: "chore: bump deps", Body: "BREAKING-CHANGE: needs go 1.22"},
+		{Hash: "eeeeeeeeee", Subject: "update readme (#7)"},
+		{Hash: "ffffffffff", Subject: "Merge pull request #7 from acme/docs", Body: "Document the changelog"},
+	}}
+
+	testCases := []struct {
+		name     string
+		prp      simver.PRProvider
+		expected string
+	}{
+		{
+			name: "commits only",
+			prp:  nil,
+			expected: `## v1.3.0
+
+### Breaking Changes
+
+- drop old flags (ccccccc)
+- bump deps (ddddddd)
+
+### Features
+
+- **cli:** add changelog command (aaaaaaa)
+
+### Bug Fixes
+
+- handle empty tags (#8) (bbbbbbb)
+
+### Other Changes
+
+- update readme (#7) (eeeeeee)
+- Merge pull request #7 from acme/docs (fffffff)
+
+**Full Changelog**: v1.2.0...v1.3.0
+`,
+		},
+		{
+			name: "merged prs",
+			prp: &fakePRs{byNumber: map[int]*simver.PRDetails{
+				7: {Number: 7, Merged: true, Title: "Document the changelog", Labels: []string{"documentation"}},
+				8: {Number: 8, Merged: false, Title: "not merged"},
+			}},
+			expected: `## v1.3.0
+
+### Breaking Changes
+
+- drop old flags (ccccccc)
+- bump deps (ddddddd)
+
+### Features
+
+- **cli:** add changelog command (aaaaaaa)
+
+### Bug Fixes
+
+- handle empty tags (#8) (bbbbbbb)
+
+### Documentation
+
+- Document the changelog (#7)
+
+**Full Changelog**: v1.2.0...v1.3.0
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cl, err := simver.BuildChangelog(ctx, gp, tc.prp, simver.DefaultConfig(), "v1.2.0", "v1.3.0")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cl.Markdown())
+		})
+	}
+}
+
+func TestBuildChangelogLookups(t *testing.T) {
+	ctx := context.Background()
+
+	commits := []simver.Commit{}
+	for i := 0; i < simver.MaxChangelogCommits+10; i++ {
+		commits = append(commits, simver.Commit{Hash: fmt.Sprintf("%010d", i), Subject: "fix: things (#3)"})
+	}
+	commits = append(commits, simver.Commit{Hash: "ffffffffff", Subject: "feat: too old (#4)"})
+
+	prp := &fakePRs{byNumber: map[int]*simver.PRDetails{
+		3: {Number: 3, Merged: true, Title: "fix: things"},
+		4: {Number: 4, Merged: true, Title: "feat: too old"},
+	}}
+
+	cl, err := simver.BuildChangelog(ctx, &fakeCommits{commits: commits}, prp, simver.DefaultConfig(), "", "v1.0.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, prp.lookups, "each pr is looked up once, and only within the limit")
+	require.Len(t, cl.Sections, 1)
+	assert.Len(t, cl.Sections[0].Entries, 1)
+}
+
+func TestChangelogGroupsFromConfig(t *testing.T) {
+	ctx := context.Background()
+
+	gp := &fakeCommits{commits: []simver.Commit{
+		{Hash: "aaaaaaaaaa", Subject: "feat: add flag"},
+		{Hash: "bbbbbbbbbb", Subject: "ci: cache modules"},
+	}}
+
+	cfg := simver.DefaultConfig()
+	cfg.Changelog.Groups = []*simver.ChangelogGroupConfig{
+		{Title: "Infrastructure", Types: []string{"ci", "build"}},
+	}
+
+	cl, err := simver.BuildChangelog(ctx, gp, nil, cfg, "", "v0.1.0")
+	require.NoError(t, err)
+
+	require.Len(t, cl.Sections, 2)
+	assert.Equal(t, "Infrastructure", cl.Sections[0].Title)
+	assert.Equal(t, "cache modules", cl.Sections[0].Entries[0].Summary)
+	assert.Equal(t, "Other Changes", cl.Sections[1].Title)
+	assert.Equal(t, "feat", cl.Sections[1].Entries[0].Type)
+
+	out, err := cl.JSON()
+	require.NoError(t, err)
+	assert.Contains(t, out, `"version": "v0.1.0"`)
+	assert.NotContains(t, out, `"previous"`)
+}
+
+func TestPreviousRelease(t *testing.T) {
+	tags := simver.Tags{
+		simver.Tag{Name: "v1.1.0"},
+		simver.Tag{Name: "v1.2.0"},
+		simver.Tag{Name: "v1.2.1-pr3+1"},
+		simver.Tag{Name: "v1.3.0-reserved"},
+		simver.Tag{Name: "v1.3.0"},
+		simver.Tag{Name: "v1.4.0"},
+		simver.Tag{Name: "tools/v1.2.5"},
+	}
+
+	assert.Equal(t, "v1.2.0", simver.PreviousRelease(tags, "v1.3.0"))
+	assert.Equal(t, "", simver.PreviousRelease(tags, "v1.1.0"))
+	assert.Equal(t, "", simver.PreviousRelease(tags, "tools/v1.2.5"))
+	assert.Equal(t, "", simver.PreviousRelease(tags, "not-a-version"))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
 	"runtime"
@@ -32,6 +33,7 @@ func init() {
 type DefaultLoggerOpts struct {
 	Level       zerolog.Level
 	CommandName string
+	Out         io.Writer // defaults to os.Stdout
 }
 
 func ApplyDefaultLoggerContext(ctx context.Context, opts *DefaultLoggerOpts) context.Context {
@@ -42,7 +44,12 @@ func ApplyDefaultLoggerContext(ctx context.Context, opts *DefaultLoggerOpts) con
 
 func DefaultLogger(opts *DefaultLoggerOpts) *zerolog.Logger {
 
-	consoleOutput := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.StampMicro, NoColor: false}
+	out := opts.Out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	consoleOutput := zerolog.ConsoleWriter{Out: out, TimeFormat: time.StampMicro, NoColor: false}
 
 	pretty := pp.New()
 
@@ -126,9 +133,9 @@ func DefaultLogger(opts *DefaultLoggerOpts) *zerolog.Logger {
 		l = l.Str("cmd", opts.CommandName)
 	}
 
-	out := l.Logger().Level(opts.Level)
+	logger := l.Logger().Level(opts.Level)
 
-	return &out
+	return &logger
 }
 
 func ZeroLogCallerMarshalFunc(pc uintptr, file string, line int) string {
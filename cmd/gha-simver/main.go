@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/rs/zerolog"
+	"github.com/spf13/afero"
 	"github.com/walteh/simver"
 	"github.com/walteh/simver/cli"
 	"github.com/walteh/simver/gitexec"
@@ -26,20 +27,42 @@ func main() {
 
 	zerolog.SetGlobalLevel(zerolog.DebugLevel)
 
-	_, tagreader, tagwriter, _, prr, err := gitexec.BuildGitHubActionsProviders(*path, *readOnly)
+	gitprov, tagreader, tagwriter, prp, prr, err := gitexec.BuildGitHubActionsProviders(*path, *readOnly)
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("error creating provider")
 		os.Exit(1)
 	}
 
-	ee, _, err := simver.LoadExecutionFromPR(ctx, tagreader, prr)
+	cfg, err := simver.LoadConfig(afero.NewBasePathFs(afero.NewOsFs(), *path))
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("error loading config")
+		os.Exit(1)
+	}
+
+	tagreader, tagwriter, err = gitexec.WithConfiguredStorage(cfg, tagreader, tagwriter)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("error configuring tag storage")
+		os.Exit(1)
+	}
+
+	ee, _, err := simver.LoadExecutionFromPR(ctx, gitprov, tagreader, prr, cfg)
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msgf("error loading execution")
 		// fmt.Println(terrors.FormatErrorCaller(err))
 		os.Exit(1)
 	}
 
-	tt := simver.Calculate(ctx, ee).CalculateNewTagsRaw(ctx)
+	calc, err := simver.Calculate(ctx, ee, cfg)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("error calculating")
+		os.Exit(1)
+	}
+
+	tt, err := calc.CalculateNewTagsRaw(ctx)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("error calculating new tags")
+		os.Exit(1)
+	}
 
 	tags := tt.ApplyRefs(ee.ProvideRefs())
 
@@ -51,4 +74,40 @@ func main() {
 		os.Exit(1)
 	}
 
+	if cfg.FloatingTags {
+		existing, err := tagreader.TagsFromPattern(ctx, "*")
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msgf("error listing tags")
+			os.Exit(1)
+		}
+
+		floating := simver.FloatingTags(simver.LiveReleases(ee, tt, ee.ProvideRefs(), cfg), existing)
+
+		err = tagwriter.MoveTags(ctx, floating...)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msgf("error moving floating tags")
+			os.Exit(1)
+		}
+	}
+
+	releases, err := simver.PlanReleases(ee, tt, ee.ProvideRefs(), cfg)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("error planning releases")
+		os.Exit(1)
+	}
+
+	if len(releases) > 0 {
+		releaser, err := gitexec.BuildGitHubActionsReleaser(*readOnly)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("error creating releaser")
+			os.Exit(1)
+		}
+
+		err = simver.CreateReleases(ctx, gitprov, prp, tagreader, releaser, cfg, releases)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msgf("error creating releases")
+			os.Exit(1)
+		}
+	}
+
 }
@@ -4,48 +4,123 @@ import (
 	"context"
 	"flag"
 	"os"
+	"regexp"
 	"time"
 
 	"github.com/rs/zerolog"
+	"github.com/spf13/afero"
 	"github.com/walteh/simver"
 	"github.com/walteh/simver/cli"
 	"github.com/walteh/simver/gitexec"
+	"gitlab.com/tozd/go/errors"
 )
 
 var path = flag.String("path", ".", "path to the repository")
 var readOnly = flag.Bool("read-only", true, "read-only mode")
 
 var wait = flag.String("wait", "2m", "time to wait for tag")
-var interval = flag.String("interval", "5s", "interval to check for tag")
+var interval = flag.String("interval", "5s", "initial interval to check for tag, doubled after every miss")
+var maxInterval = flag.String("max-interval", "30s", "maximum interval to check for tag")
 
-func init() {
-	flag.Parse()
+var role = flag.String("role", "", "only wait for tags with this role (release, pr, base, reserved, build, other) - defaults to pr for unmerged pull requests")
+var pattern = flag.String("pattern", "", "only wait for tags matching this regular expression")
+var prefix = flag.String("prefix", "", "module prefix of the tags to wait for, e.g. tools/")
+
+type target struct {
+	commit string
+	query  *simver.TagQuery
 }
 
-func check(ctx context.Context, gp simver.GitProvider, tr simver.TagReader, tw simver.TagWriter, head string) (*simver.Tag, bool, error) {
-	if head == "" {
-		return nil, false, nil
+func buildQuery(format *simver.TagFormat) (*simver.TagQuery, error) {
+	q := &simver.TagQuery{
+		Prefix: *prefix,
+		Role:   simver.TagRole(*role),
+		Format: format,
+	}
+
+	switch q.Role {
+	case "", simver.TagRoleRelease, simver.TagRolePR, simver.TagRoleBase, simver.TagRoleReserved, simver.TagRoleBuild, simver.TagRoleChannel, simver.TagRoleOther:
+	default:
+		return nil, errors.Errorf("invalid role %q", *role)
+	}
+
+	if *pattern != "" {
+		reg, err := regexp.Compile(*pattern)
+		if err != nil {
+			return nil, errors.Errorf("compiling pattern: %w", err)
+		}
+		q.Pattern = reg
 	}
 
-	_, err := tw.FetchTags(ctx)
+	return q, nil
+}
+
+// resolveTarget figures out which commit we expect simver to tag, and what the tag should look like
+func resolveTarget(ctx context.Context, eventName string, git simver.GitProvider, prr simver.PRResolver, format *simver.TagFormat) (*target, error) {
+	q, err := buildQuery(format)
 	if err != nil {
-		return nil, false, err
+		return nil, err
+	}
+
+	switch eventName {
+	case "push":
+		head, err := git.CommitFromRef(ctx, "HEAD")
+		if err != nil {
+			return nil, errors.Errorf("getting head commit: %w", err)
+		}
+
+		return &target{commit: head, query: q}, nil
+	case "pull_request", "pull_request_target":
+		pr, err := prr.CurrentPR(ctx)
+		if err != nil {
+			return nil, errors.Errorf("getting current pr: %w", err)
+		}
+
+		if pr.Merged {
+			return &target{commit: pr.MergeCommit, query: q}, nil
+		}
+
+		if q.Role == "" {
+			q.Role = simver.TagRolePR
+		}
+
+		if q.Role == simver.TagRolePR || q.Role == simver.TagRoleBase {
+			q.PR = pr.Number
+		}
+
+		return &target{commit: pr.HeadCommit, query: q}, nil
+	default:
+		return nil, errors.Errorf("unsupported event %q - this action is only useful for push and pull_request events", eventName)
+	}
+}
+
+func check(ctx context.Context, tr simver.TagReader, tgt *target) (*simver.Tag, bool, error) {
+	if tgt.commit == "" {
+		return nil, false, nil
 	}
 
-	tags, err := tr.TagsFromCommit(ctx, head)
+	tags, err := tr.TagsFromRemote(ctx)
 	if err != nil {
 		return nil, false, err
 	}
 
-	if len(tags) == 0 {
-		return nil, false, nil
+	onCommit := make(simver.Tags, 0)
+	for _, tag := range tags {
+		if tag.Ref == tgt.commit {
+			onCommit = append(onCommit, tag)
+		}
+	}
 
+	tag, ok := onCommit.Highest(tgt.query)
+	if !ok {
+		return nil, false, nil
 	}
 
-	return &tags[0], true, nil
+	return &tag, true, nil
 }
 
 func main() {
+	flag.Parse()
 
 	// get commit to wait on
 	ctx := context.Background()
@@ -55,10 +130,6 @@ func main() {
 	})
 
 	eventName := os.Getenv("GITHUB_EVENT_NAME")
-	if eventName != "push" {
-		zerolog.Ctx(ctx).Error().Str("event_name", eventName).Msg("not a push event - this action is only useful for push events")
-		os.Exit(1)
-	}
 
 	wait, err := time.ParseDuration(*wait)
 	if err != nil {
@@ -74,25 +145,48 @@ func main() {
 		panic(err)
 	}
 
+	maxInterval, err := time.ParseDuration(*maxInterval)
+	if err != nil {
+		panic(err)
+	}
+
 	zerolog.SetGlobalLevel(zerolog.DebugLevel)
 
 	ctx, can := context.WithTimeout(ctx, wait)
 
 	defer can()
 
-	git, tr, tw, _, _, err := gitexec.BuildGitHubActionsProviders(*path, *readOnly)
+	git, tr, tw, _, prr, err := gitexec.BuildGitHubActionsProviders(*path, *readOnly)
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("error creating provider")
 		os.Exit(1)
 	}
 
-	head, err := git.GetHeadRef(ctx)
+	cfg, err := simver.LoadConfig(afero.NewBasePathFs(afero.NewOsFs(), *path))
 	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("error getting head ref")
+		zerolog.Ctx(ctx).Error().Err(err).Msg("error loading config")
 		os.Exit(1)
 	}
 
-	ctx = zerolog.Ctx(ctx).With().Str("head", head).Logger().WithContext(ctx)
+	format, err := cfg.TagFormat()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("error building tag format")
+		os.Exit(1)
+	}
+
+	tr, _, err = gitexec.WithConfiguredStorage(cfg, tr, tw)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("error configuring tag storage")
+		os.Exit(1)
+	}
+
+	tgt, err := resolveTarget(ctx, eventName, git, prr, format)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("event_name", eventName).Msg("error resolving commit to wait on")
+		os.Exit(1)
+	}
+
+	ctx = zerolog.Ctx(ctx).With().Str("head", tgt.commit).Logger().WithContext(ctx)
 
 	zerolog.Ctx(ctx).Info().Msg("waiting for tag on head commit")
 
@@ -101,13 +195,13 @@ func main() {
 		select {
 		case <-ctx.Done():
 			{
-				zerolog.Ctx(ctx).Error().Err(err).Msg("timeout waiting for tag")
+				zerolog.Ctx(ctx).Error().Err(ctx.Err()).Msg("timeout waiting for tag")
 				os.Exit(1)
 			}
 		default:
 			{
-				zerolog.Ctx(ctx).Info().Msg("checking for commit for commit")
-				tg, ok, err := check(ctx, git, tr, tw, head)
+				zerolog.Ctx(ctx).Info().Msg("checking remote for tag on commit")
+				tg, ok, err := check(ctx, tr, tgt)
 				if err != nil {
 					zerolog.Ctx(ctx).Error().Err(err).Msg("error checking for tag")
 					panic(err)
@@ -115,12 +209,24 @@ func main() {
 
 				if ok {
 					zerolog.Ctx(ctx).Info().Str("name", tg.Name).Msg("tag found")
+
+					err = gitexec.WriteGitHubActionsOutput("tag", tg.Name)
+					if err != nil {
+						zerolog.Ctx(ctx).Error().Err(err).Msg("error writing output")
+						os.Exit(1)
+					}
+
 					os.Exit(0)
 				}
 
 				zerolog.Ctx(ctx).Info().Dur("remaining", time.Until(end)).Dur("interval", interval).Msg("tag not found, waiting")
 
-				time.Sleep(interval)
+				select {
+				case <-ctx.Done():
+				case <-time.After(interval):
+				}
+
+				interval = min(interval*2, maxInterval)
 			}
 		}
 	}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+	"github.com/walteh/simver/simvertest"
+)
+
+func TestResolveTarget(t *testing.T) {
+	ctx := context.Background()
+
+	repo := simvertest.NewRepo()
+	pushed := repo.Push("main", "feat: push")
+
+	tgt, err := resolveTarget(ctx, "push", repo, repo, simver.DefaultTagFormat)
+	require.NoError(t, err)
+	assert.Equal(t, pushed, tgt.commit)
+	assert.Equal(t, simver.TagRole(""), tgt.query.Role)
+
+	num := repo.OpenPR("feature", "main", "feat: feature")
+	repo.CreateBranch("feature", "main")
+	head := repo.PushToPR(num, "feat: feature")
+
+	tgt, err = resolveTarget(ctx, "pull_request", repo, repo, simver.DefaultTagFormat)
+	require.NoError(t, err)
+	assert.Equal(t, head, tgt.commit)
+	assert.Equal(t, simver.TagRolePR, tgt.query.Role)
+	assert.Equal(t, num, tgt.query.PR)
+
+	merge := repo.Merge(num)
+
+	tgt, err = resolveTarget(ctx, "pull_request_target", repo, repo, simver.DefaultTagFormat)
+	require.NoError(t, err)
+	assert.Equal(t, merge, tgt.commit)
+	assert.Equal(t, simver.TagRole(""), tgt.query.Role)
+
+	_, err = resolveTarget(ctx, "workflow_dispatch", repo, repo, simver.DefaultTagFormat)
+	assert.ErrorContains(t, err, `unsupported event "workflow_dispatch"`)
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+
+	repo := simvertest.NewRepo()
+	skipped := repo.Push("main", "chore: skipped")
+	released := repo.Push("main", "feat: released")
+
+	require.NoError(t, repo.CreateTags(ctx,
+		simver.Tag{Name: "v1.3.0+build.10", Ref: skipped},
+		simver.Tag{Name: "v1.4.0", Ref: released},
+		simver.Tag{Name: "v1.4.0+build.11", Ref: released},
+	))
+
+	testCases := []struct {
+		name     string
+		commit   string
+		expected string
+		found    bool
+	}{
+		{
+			name:     "release wins over its push build",
+			commit:   released,
+			expected: "v1.4.0",
+			found:    true,
+		},
+		{
+			name:     "push build of a skipped push",
+			commit:   skipped,
+			expected: "v1.3.0+build.10",
+			found:    true,
+		},
+		{
+			name:   "untagged commit",
+			commit: repo.Push("main", "chore: untagged"),
+			found:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tag, ok, err := check(ctx, repo, &target{commit: tc.commit, query: &simver.TagQuery{}})
+			require.NoError(t, err)
+			assert.Equal(t, tc.found, ok)
+			if tc.found {
+				assert.Equal(t, tc.expected, tag.Name)
+			}
+		})
+	}
+}
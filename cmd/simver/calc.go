@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/afero"
+	"github.com/walteh/simver"
+	"github.com/walteh/simver/gitexec"
+	"gitlab.com/tozd/go/errors"
+)
+
+var calcCommand = &command{
+	usage: "calculate the version of the current commit without pushing anything",
+	run:   runCalc,
+}
+
+func runCalc(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("calc", flag.ContinueOnError)
+	path := flags.String("path", ".", "path to the repository")
+	local := flags.Bool("local", false, "calculate a developer version for the working copy instead of using github actions state")
+	format := flags.String("format", "semver", "output format: semver, go for a version go get accepts (requires --local), or docker and docker-json for image tags")
+	modulePath := flags.String("module", "", "go module path to validate --format=go output against")
+	debug := flags.Bool("debug", false, "enable debug logging")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *debug {
+		ctx = zerolog.Ctx(ctx).Level(zerolog.DebugLevel).WithContext(ctx)
+	}
+
+	switch *format {
+	case "semver", "docker", "docker-json":
+	case "go":
+		if !*local {
+			return errors.New("--format=go requires --local")
+		}
+	default:
+		return errors.Errorf("unknown format %q", *format)
+	}
+
+	var version string
+	var live bool
+
+	if *local {
+		version, err = calcLocal(ctx, *path, *format, *modulePath)
+	} else {
+		version, live, err = calcActions(ctx, *path)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *format == "docker" || *format == "docker-json" {
+		return printDockerTags(version, live, *format == "docker-json")
+	}
+
+	fmt.Println(version)
+
+	return nil
+}
+
+func printDockerTags(version string, live bool, asJSON bool) error {
+	tags, err := simver.DockerTags(version, live)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		byt, err := json.Marshal(tags)
+		if err != nil {
+			return errors.Errorf("marshalling docker tags: %w", err)
+		}
+		fmt.Println(string(byt))
+		return nil
+	}
+
+	fmt.Println(strings.Join(tags, "\n"))
+
+	return nil
+}
+
+func calcLocal(ctx context.Context, path string, format string, modulePath string) (string, error) {
+	gp, tr, _, _, err := gitexec.BuildLocalProviders(afero.NewBasePathFs(afero.NewOsFs(), path))
+	if err != nil {
+		return "", errors.Errorf("creating local providers: %w", err)
+	}
+
+	state, err := simver.NewLocalProjectState(ctx, gp, tr)
+	if err != nil {
+		return "", errors.Errorf("loading local state: %w", err)
+	}
+
+	zerolog.Ctx(ctx).Debug().
+		Str("commit", state.Commit).
+		Str("branch", state.Branch).
+		Bool("dirty", state.Dirty).
+		Int("commits_since_tag", state.CommitsSinceTag).
+		Msg("loaded local state")
+
+	if format == "go" {
+		return state.GoVersion(modulePath)
+	}
+
+	return state.DevVersion(ctx)
+}
+
+// calcActions returns the tag of the current commit, and whether it is a live release on the root branch
+func calcActions(ctx context.Context, path string) (string, bool, error) {
+	cv, err := loadActionsVersion(ctx, path)
+	if err != nil {
+		return "", false, err
+	}
+
+	return cv.tag, cv.live, nil
+}
+
+// actionsVersion is the version of the commit a github actions run is about
+type actionsVersion struct {
+	tag    string
+	commit string
+	pr     int
+	build  int
+	live   bool
+}
+
+// loadActionsVersion reads the version of the current commit in github actions, without creating anything
+func loadActionsVersion(ctx context.Context, path string) (*actionsVersion, error) {
+	gp, tr, tw, _, prr, err := gitexec.BuildGitHubActionsProviders(path, true)
+	if err != nil {
+		return nil, errors.Errorf("creating providers: %w", err)
+	}
+
+	cfg, err := simver.LoadConfig(afero.NewBasePathFs(afero.NewOsFs(), path))
+	if err != nil {
+		return nil, errors.Errorf("loading config: %w", err)
+	}
+
+	tr, _, err = gitexec.WithConfiguredStorage(cfg, tr, tw)
+	if err != nil {
+		return nil, errors.Errorf("configuring tag storage: %w", err)
+	}
+
+	ee, _, err := simver.LoadExecutionFromPR(ctx, gp, tr, prr, cfg)
+	if err != nil {
+		return nil, errors.Errorf("loading execution: %w", err)
+	}
+
+	return currentVersion(ctx, ee, cfg)
+}
+
+// currentVersion returns the version gha-simver tagged the current commit with, picked the same way
+// gha-wait-on-simver picks it. Only if the commit has no tag yet is it calculated like gha-simver would.
+func currentVersion(ctx context.Context, ex simver.Execution, cfg *simver.Config) (*actionsVersion, error) {
+	format, err := cfg.TagFormat()
+	if err != nil {
+		return nil, errors.Errorf("building tag format: %w", err)
+	}
+
+	refs := ex.ProvideRefs()
+
+	commit := refs.Head()
+	if ex.IsMerge() {
+		commit = refs.Merge()
+	}
+
+	// reserved and base tags are markers for other calculations, not the version of the commit they are on
+	versionTags := func(tags simver.Tags) simver.Tags {
+		out := simver.Tags{}
+		for _, t := range tags {
+			if role := format.RoleOf(t.Name); t.Ref == commit && role != simver.TagRoleReserved && role != simver.TagRoleBase {
+				out = append(out, t)
+			}
+		}
+		return out
+	}
+
+	tags := versionTags(ex.HeadCommitTags())
+	if len(tags) == 0 {
+		calc, err := simver.Calculate(ctx, ex, cfg)
+		if err != nil {
+			return nil, errors.Errorf("calculating: %w", err)
+		}
+
+		out, err := calc.CalculateNewTagsRaw(ctx)
+		if err != nil {
+			return nil, errors.Errorf("calculating new tags: %w", err)
+		}
+
+		tags = versionTags(out.ApplyRefs(refs))
+	}
+
+	q := &simver.TagQuery{Format: format}
+	if ex.PR() != 0 && !ex.IsMerge() {
+		q.Role = simver.TagRolePR
+		q.PR = ex.PR()
+	}
+
+	tag, ok := tags.Highest(q)
+	if !ok {
+		return nil, errors.Errorf("no tag found or calculated for commit %s", commit)
+	}
+
+	v, err := simver.ParseVersion(format.Canonical(tag.Name))
+	if err != nil {
+		return nil, errors.Errorf("parsing %s: %w", tag.Name, err)
+	}
+
+	cv := &actionsVersion{
+		tag:    tag.Name,
+		commit: commit,
+		pr:     ex.PR(),
+		live:   v.Role == simver.TagRoleRelease && simver.ReleasesOnRoot(ex, cfg),
+	}
+
+	// a patch of an older line is not live, even if it was released on the root branch
+	if top, ok := ex.RootBranchTags().Versions().WithPrefix(v.Prefix).WithRole(simver.TagRoleRelease).Highest(); ok && v.Compare(top) < 0 {
+		cv.live = false
+	}
+
+	// pr and channel tags carry their build number, pushes have it in the build tag next to the release
+
+	cv.build = v.BuildNumber
+
+	for _, t := range tags {
+		if cv.build != 0 {
+			break
+		}
+		if b, err := simver.ParseVersion(format.Canonical(t.Name)); err == nil && b.Role == simver.TagRoleBuild && b.Core().Compare(v.Core()) == 0 {
+			cv.build = b.BuildNumber
+		}
+	}
+
+	return cv, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+	"github.com/walteh/simver/simvertest"
+)
+
+func TestCurrentVersion(t *testing.T) {
+	ctx := context.Background()
+
+	repo := simvertest.NewRepo()
+	cfg := simver.DefaultConfig()
+	cfg.PushBuilds = true
+
+	current := func() *actionsVersion {
+		t.Helper()
+		ex, _, err := simver.LoadExecutionFromPR(ctx, repo, repo, repo, cfg)
+		require.NoError(t, err)
+		cv, err := currentVersion(ctx, ex, cfg)
+		require.NoError(t, err)
+		return cv
+	}
+
+	pushed := repo.Push("main", "feat: first")
+
+	// before gha-simver ran, the version is calculated
+	assert.Equal(t, &actionsVersion{tag: "v0.2.0", commit: pushed, build: 1, live: true}, current())
+
+	_, err := repo.Simver(ctx, cfg)
+	require.NoError(t, err)
+
+	// afterwards it is read from the commit, a new calculation would skip the tagged commit
+	assert.Equal(t, &actionsVersion{tag: "v0.2.0", commit: pushed, build: 1, live: true}, current())
+
+	repo.CreateBranch("feature", "main")
+	pr := repo.OpenPR("feature", "main", "feat: feature")
+	head := repo.PushToPR(pr, "feat: feature")
+
+	_, err = repo.Simver(ctx, cfg)
+	require.NoError(t, err)
+
+	// a new calculation would move on to the next pr build
+	assert.Equal(t, &actionsVersion{tag: "v0.3.0-pr1+1", commit: head, pr: pr, build: 1}, current())
+}
+
+func TestCurrentVersionOfLine(t *testing.T) {
+	ctx := context.Background()
+
+	repo := simvertest.NewRepo()
+	cfg := simver.DefaultConfig()
+	cfg.Lines = []*simver.LineConfig{{Branch: "release/*"}}
+
+	current := func() *actionsVersion {
+		t.Helper()
+		ex, _, err := simver.LoadExecutionFromPR(ctx, repo, repo, repo, cfg)
+		require.NoError(t, err)
+		cv, err := currentVersion(ctx, ex, cfg)
+		require.NoError(t, err)
+		return cv
+	}
+
+	repo.Push("main", "feat: first")
+	_, err := repo.Simver(ctx, cfg)
+	require.NoError(t, err)
+
+	repo.CreateBranch("release/0.2", "main")
+
+	repo.Push("main", "feat: second")
+	_, err = repo.Simver(ctx, cfg)
+	require.NoError(t, err)
+	assert.True(t, current().live)
+
+	// a push to a maintenance line is a release, but the floating aliases stay on the root branch
+	hotfix := repo.Push("release/0.2", "fix: hotfix")
+	assert.Equal(t, &actionsVersion{tag: "v0.2.1", commit: hotfix}, current())
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/afero"
+	"github.com/walteh/simver"
+	"github.com/walteh/simver/gitexec"
+	"gitlab.com/tozd/go/errors"
+)
+
+var changelogCommand = &command{
+	usage: "render the changes between two releases as markdown or json",
+	run:   runChangelog,
+}
+
+func runChangelog(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("changelog", flag.ContinueOnError)
+	path := flags.String("path", ".", "path to the repository")
+	to := flags.String("to", "", "release to describe, defaults to the most recent release on HEAD")
+	from := flags.String("from", "", "release to start from, defaults to the release before --to")
+	format := flags.String("format", "markdown", "output format: markdown or json")
+	github := flags.Bool("github", false, "describe merged prs instead of commits, using the github actions environment")
+	debug := flags.Bool("debug", false, "enable debug logging")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *debug {
+		ctx = zerolog.Ctx(ctx).Level(zerolog.DebugLevel).WithContext(ctx)
+	}
+
+	switch *format {
+	case "markdown", "json":
+	default:
+		return errors.Errorf("unknown format %q", *format)
+	}
+
+	fls := afero.NewBasePathFs(afero.NewOsFs(), *path)
+
+	cfg, err := simver.LoadConfig(fls)
+	if err != nil {
+		return errors.Errorf("loading config: %w", err)
+	}
+
+	var gp simver.GitProvider
+	var tr simver.TagReader
+	var prp simver.PRProvider
+
+	if *github {
+		gp, tr, _, prp, _, err = gitexec.BuildGitHubActionsProviders(*path, true)
+		if err != nil {
+			return errors.Errorf("creating providers: %w", err)
+		}
+	} else {
+		gp, tr, _, _, err = gitexec.BuildLocalProviders(fls)
+		if err != nil {
+			return errors.Errorf("creating local providers: %w", err)
+		}
+	}
+
+	if *to == "" {
+		head, err := tr.TagsFromBranch(ctx, "HEAD")
+		if err != nil {
+			return errors.Errorf("reading tags on HEAD: %w", err)
+		}
+
+		mrlt := simver.MostRecentLiveTag(&simver.LocalProjectState{Tags: head})
+		if mrlt.IsZero() {
+			return errors.New("no release on HEAD, pass --to")
+		}
+		*to = mrlt.String()
+	}
+
+	if *from == "" {
+		all, err := tr.TagsFromPattern(ctx, "*")
+		if err != nil {
+			return errors.Errorf("listing tags: %w", err)
+		}
+
+		*from = simver.PreviousRelease(all, *to)
+	}
+
+	cl, err := simver.BuildChangelog(ctx, gp, prp, cfg, *from, *to)
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		out, err := cl.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Println(out)
+		return nil
+	}
+
+	fmt.Print(cl.Markdown())
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/afero"
+	"github.com/walteh/simver"
+	"github.com/walteh/simver/gitexec"
+	"github.com/walteh/simver/version"
+	"gitlab.com/tozd/go/errors"
+)
+
+var ldflagsCommand = &command{
+	usage: "print -X linker flags that embed the calculated version, for go build -ldflags",
+	run:   runLDFlags,
+}
+
+func runLDFlags(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("ldflags", flag.ContinueOnError)
+	path := flags.String("path", ".", "path to the repository")
+	local := flags.Bool("local", false, "calculate a developer version for the working copy instead of using github actions state")
+	pkg := flags.String("package", version.Package, "package that declares the Version, Commit, PR and Build variables")
+	debug := flags.Bool("debug", false, "enable debug logging")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *debug {
+		ctx = zerolog.Ctx(ctx).Level(zerolog.DebugLevel).WithContext(ctx)
+	}
+
+	var info version.Info
+
+	if *local {
+		info, err = ldflagsLocal(ctx, *path)
+	} else {
+		info, err = ldflagsActions(ctx, *path)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(version.LDFlags(*pkg, info))
+
+	return nil
+}
+
+func ldflagsLocal(ctx context.Context, path string) (version.Info, error) {
+	gp, tr, _, _, err := gitexec.BuildLocalProviders(afero.NewBasePathFs(afero.NewOsFs(), path))
+	if err != nil {
+		return version.Info{}, errors.Errorf("creating local providers: %w", err)
+	}
+
+	state, err := simver.NewLocalProjectState(ctx, gp, tr)
+	if err != nil {
+		return version.Info{}, errors.Errorf("loading local state: %w", err)
+	}
+
+	v, err := state.DevVersion(ctx)
+	if err != nil {
+		return version.Info{}, err
+	}
+
+	return version.Info{Version: v, Commit: state.Commit}, nil
+}
+
+func ldflagsActions(ctx context.Context, path string) (version.Info, error) {
+	cv, err := loadActionsVersion(ctx, path)
+	if err != nil {
+		return version.Info{}, err
+	}
+
+	return version.Info{Version: cv.tag, Commit: cv.commit, PR: cv.pr, Build: cv.build}, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/rs/zerolog"
+	"github.com/walteh/simver/cli"
+)
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+var commands = map[string]*command{
+	"calc":          calcCommand,
+	"changelog":     changelogCommand,
+	"ldflags":       ldflagsCommand,
+	"simulate":      simulateCommand,
+	"write-version": writeVersionCommand,
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: simver <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", name, commands[name].usage)
+	}
+}
+
+func main() {
+
+	ctx := context.Background()
+
+	ctx = cli.ApplyDefaultLoggerContext(ctx, &cli.DefaultLoggerOpts{
+		CommandName: "simver",
+		Level:       zerolog.InfoLevel,
+		Out:         os.Stderr,
+	})
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	err := cmd.run(ctx, os.Args[2:])
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("command", os.Args[1]).Msg("command failed")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/afero"
+	"github.com/walteh/simver/simvertest"
+	"gitlab.com/tozd/go/errors"
+)
+
+var simulateCommand = &command{
+	usage: "replay scenario files against an in-memory repository and report the tags and violations",
+	run:   runSimulate,
+}
+
+func runSimulate(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	debug := flags.Bool("debug", false, "enable debug logging")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *debug {
+		ctx = zerolog.Ctx(ctx).Level(zerolog.DebugLevel).WithContext(ctx)
+	} else {
+		// every step runs simver, whose info logs would bury the report
+		ctx = zerolog.Ctx(ctx).Level(zerolog.WarnLevel).WithContext(ctx)
+	}
+
+	if flags.NArg() == 0 {
+		return errors.New("at least one scenario file is required")
+	}
+
+	failed := 0
+
+	for _, path := range flags.Args() {
+		s, err := simvertest.LoadScenario(afero.NewOsFs(), path)
+		if err != nil {
+			return err
+		}
+
+		res, err := s.Run(ctx)
+		if err != nil {
+			return errors.Errorf("%s: %w", path, err)
+		}
+
+		fmt.Println(res.Report())
+
+		if !res.OK() {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return errors.Errorf("%d of %d scenarios failed", failed, flags.NArg())
+	}
+
+	return nil
+}
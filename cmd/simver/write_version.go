@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/afero"
+	"github.com/walteh/simver"
+	"gitlab.com/tozd/go/errors"
+)
+
+var writeVersionCommand = &command{
+	usage: "update the version_files in .simver.yaml to the calculated version, without committing",
+	run:   runWriteVersion,
+}
+
+func runWriteVersion(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("write-version", flag.ContinueOnError)
+	path := flags.String("path", ".", "path to the repository")
+	version := flags.String("version", "", "version to write, calculated like simver calc if empty")
+	local := flags.Bool("local", false, "calculate a developer version for the working copy instead of using github actions state")
+	debug := flags.Bool("debug", false, "enable debug logging")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *debug {
+		ctx = zerolog.Ctx(ctx).Level(zerolog.DebugLevel).WithContext(ctx)
+	}
+
+	fls := afero.NewBasePathFs(afero.NewOsFs(), *path)
+
+	cfg, err := simver.LoadConfig(fls)
+	if err != nil {
+		return errors.Errorf("loading config: %w", err)
+	}
+
+	if len(cfg.VersionFiles) == 0 {
+		return errors.Errorf("no version_files in %s", simver.ConfigFileName)
+	}
+
+	if *version == "" {
+		if *local {
+			*version, err = calcLocal(ctx, *path, "semver", "")
+		} else {
+			*version, _, err = calcActions(ctx, *path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := simver.ParseVersion(*version); err != nil {
+		return errors.Errorf("invalid version %q: %w", *version, err)
+	}
+
+	return simver.WriteVersionFiles(ctx, fls, cfg, *version)
+}
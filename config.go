@@ -0,0 +1,370 @@
+package simver
+
+import (
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/spf13/afero"
+	"gitlab.com/tozd/go/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const ConfigFileName = ".simver.yaml"
+
+const (
+	StorageTags         = "tags"         // every tag is a real tag
+	StorageReservations = "reservations" // reserved and base tags are hidden refs
+	StorageRefs         = "refs"         // pr, base, reserved and build tags are hidden refs
+)
+
+// Config is the optional per repository configuration, read from .simver.yaml in the repository root
+type Config struct {
+	// Channels turn pushes and merges to matching branches into prereleases (e.g. v2.0.0-rc.1)
+	Channels []*ChannelConfig `yaml:"channels"`
+
+	// Lines pin maintenance branches to a major.minor line, so they only produce patches of that line (e.g. v1.4.3)
+	Lines []*LineConfig `yaml:"lines"`
+
+	// Scheme is how versions advance: semver (default) or calver (vYYYY.M.N)
+	Scheme string `yaml:"scheme"`
+
+	// PushBuilds adds a build tag (v1.2.3+build.N) next to the release tag of every push, N counts up across pushes
+	PushBuilds bool `yaml:"push_builds"`
+
+	// Skip holds the directives that skip a release
+	Skip SkipConfig `yaml:"skip"`
+
+	// Paths decides which changed files count towards a version bump
+	Paths PathsConfig `yaml:"paths"`
+
+	// BumpLabels are the pr labels that override the kind of change
+	BumpLabels BumpLabelsConfig `yaml:"bump_labels"`
+
+	// Changelog groups the entries of generated changelogs
+	Changelog ChangelogConfig `yaml:"changelog"`
+
+	// FloatingTags moves the vN and vN.M tags to every new release on the root branch, e.g. for github actions
+	FloatingTags bool `yaml:"floating_tags"`
+
+	// Releases creates GitHub releases for new live tags
+	Releases ReleasesConfig `yaml:"releases"`
+
+	// VersionFiles are updated to the calculated version by simver write-version
+	VersionFiles []*VersionFileConfig `yaml:"version_files"`
+
+	// Storage is which of simver's own tags are hidden under refs/simver/: none (tags, the default),
+	// reservations for reserved and base tags, or refs for pr, base, reserved and build tags
+	Storage string `yaml:"storage"`
+
+	// Tags overrides the shape of pr, base and reserved tags
+	Tags TagsConfig `yaml:"tags"`
+}
+
+// TagsConfig holds the tag templates, see TagFormat. Empty templates use the defaults.
+type TagsConfig struct {
+	PR       string `yaml:"pr"`       // default -pr{pr}+{build}
+	Base     string `yaml:"base"`     // default -pr{pr}+base
+	Reserved string `yaml:"reserved"` // default -reserved
+	Build    string `yaml:"build"`    // default +build.{build}
+}
+
+// ReleasesConfig controls which new tags get a GitHub release
+type ReleasesConfig struct {
+	Enabled     bool `yaml:"enabled"`     // create a release for every new version on the root branch
+	Draft       bool `yaml:"draft"`       // create the releases as drafts, to be published by hand
+	Prereleases bool `yaml:"prereleases"` // also create prereleases for pr and channel builds
+}
+
+type ChannelConfig struct {
+	Branch string `yaml:"branch"` // branch glob, e.g. release/*
+	Name   string `yaml:"name"`   // prerelease identifier, e.g. rc
+	Bump   string `yaml:"bump"`   // how the channel version is derived from the latest release: minor (default) or major
+}
+
+type LineConfig struct {
+	Branch string `yaml:"branch"` // branch glob, e.g. release/*
+	Line   string `yaml:"line"`   // major.minor, e.g. 1.4 - defaults to the major.minor at the end of the branch name
+}
+
+var lineRegex = regexp.MustCompile(`^(\d+)\.(\d+)$`)
+var branchLineRegex = regexp.MustCompile(`(?:^|[^0-9.])v?(\d+)\.(\d+)$`)
+
+// SkipConfig controls when a push or merge does not release a new version
+type SkipConfig struct {
+	Message string   `yaml:"message"` // skip when the commit message contains this, default [skip simver]
+	Labels  []string `yaml:"labels"`  // skip when the pr has one of these labels, default semver:skip
+	Paths   []string `yaml:"paths"`   // skip when every changed file matches one of these globs
+}
+
+// PathsConfig filters the changed files of a push or pr. When none of them count, the bump is downgraded.
+type PathsConfig struct {
+	Include []string `yaml:"include"` // only files matching these count, every file if empty
+	Exclude []string `yaml:"exclude"` // files matching these never count, e.g. docs/**, .github/** or *.md
+	Bump    string   `yaml:"bump"`    // what happens if no file counts: patch (default) or skip
+}
+
+type BumpLabelsConfig struct {
+	Patch string `yaml:"patch"` // default semver:patch
+	Minor string `yaml:"minor"` // default semver:minor
+	Major string `yaml:"major"` // default semver:major
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		BumpLabels: BumpLabelsConfig{
+			Patch: "semver:patch",
+			Minor: "semver:minor",
+			Major: "semver:major",
+		},
+		Skip: SkipConfig{
+			Message: "[skip simver]",
+			Labels:  []string{"semver:skip"},
+		},
+	}
+}
+
+// LoadConfig reads the config from the root of fls, falling back to the defaults if there is none
+func LoadConfig(fls afero.Fs) (*Config, error) {
+	byt, err := afero.ReadFile(fls, ConfigFileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return DefaultConfig(), nil
+		}
+		return nil, errors.Errorf("reading %s: %w", ConfigFileName, err)
+	}
+
+	cfg := DefaultConfig()
+
+	err = yaml.Unmarshal(byt, cfg)
+	if err != nil {
+		return nil, errors.Errorf("parsing %s: %w", ConfigFileName, err)
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return nil, errors.Errorf("validating %s: %w", ConfigFileName, err)
+	}
+
+	return cfg, nil
+}
+
+func (me *Config) Validate() error {
+	switch me.Scheme {
+	case "", "semver", "calver":
+	default:
+		return errors.Errorf("invalid scheme %q, expected semver or calver", me.Scheme)
+	}
+
+	switch me.Storage {
+	case "", StorageTags, StorageReservations, StorageRefs:
+	default:
+		return errors.Errorf("invalid storage %q, expected tags, reservations or refs", me.Storage)
+	}
+
+	if _, err := me.TagFormat(); err != nil {
+		return err
+	}
+
+	for _, p := range me.PathGlobs() {
+		if !validGlob(p) {
+			return errors.Errorf("invalid path pattern %q", p)
+		}
+	}
+
+	for _, g := range me.Changelog.Groups {
+		if g.Title == "" {
+			return errors.New("changelog group title is required")
+		}
+	}
+
+	for _, f := range me.VersionFiles {
+		if f.Path == "" {
+			return errors.New("version file path is required")
+		}
+
+		if _, err := f.FileType(); err != nil {
+			return err
+		}
+	}
+
+	switch me.Paths.Bump {
+	case "", "patch", "skip":
+	default:
+		return errors.Errorf("invalid paths bump %q, expected patch or skip", me.Paths.Bump)
+	}
+
+	for _, ch := range me.Channels {
+		if ch.Branch == "" {
+			return errors.New("channel branch is required")
+		}
+
+		if _, err := path.Match(ch.Branch, ""); err != nil {
+			return errors.Errorf("invalid channel branch pattern %q: %w", ch.Branch, err)
+		}
+
+		if _, err := ParseVersion("v0.0.0-" + ch.Name + ".1"); ch.Name == "" || err != nil {
+			return errors.Errorf("invalid channel name %q for branch %q", ch.Name, ch.Branch)
+		}
+
+		switch ch.Bump {
+		case "", "minor", "major":
+		default:
+			return errors.Errorf("invalid channel bump %q for branch %q", ch.Bump, ch.Branch)
+		}
+	}
+
+	for _, ln := range me.Lines {
+		if ln.Branch == "" {
+			return errors.New("line branch is required")
+		}
+
+		if _, err := path.Match(ln.Branch, ""); err != nil {
+			return errors.Errorf("invalid line branch pattern %q: %w", ln.Branch, err)
+		}
+
+		if ln.Line != "" && !lineRegex.MatchString(ln.Line) {
+			return errors.Errorf("invalid line %q for branch %q, expected major.minor", ln.Line, ln.Branch)
+		}
+	}
+
+	return nil
+}
+
+// VersionScheme returns the configured scheme
+func (me *Config) VersionScheme() Scheme {
+	if me != nil && me.Scheme == "calver" {
+		return CalVer{}
+	}
+	return SemVer{}
+}
+
+// TagFormat builds the configured tag format
+func (me *Config) TagFormat() (*TagFormat, error) {
+	if me == nil {
+		return DefaultTagFormat, nil
+	}
+	return NewTagFormat(me.Tags.PR, me.Tags.Base, me.Tags.Reserved, me.Tags.Build)
+}
+
+// ChannelFor returns the first channel whose branch pattern matches branch
+func (me *Config) ChannelFor(branch string) (*ChannelConfig, bool) {
+	if me == nil {
+		return nil, false
+	}
+
+	for _, ch := range me.Channels {
+		if ok, _ := path.Match(ch.Branch, branch); ok {
+			return ch, true
+		}
+	}
+
+	return nil, false
+}
+
+// LineFor returns the major.minor line of the first line config whose branch pattern matches branch.
+// Branches that match but have no line in their name are not considered maintenance branches.
+func (me *Config) LineFor(branch string) (major, minor int, ok bool) {
+	if me == nil {
+		return 0, 0, false
+	}
+
+	for _, ln := range me.Lines {
+		if ok, _ := path.Match(ln.Branch, branch); !ok {
+			continue
+		}
+
+		var m []string
+		if ln.Line != "" {
+			m = lineRegex.FindStringSubmatch(ln.Line)
+		} else {
+			m = branchLineRegex.FindStringSubmatch(branch)
+		}
+
+		if m == nil {
+			continue
+		}
+
+		major, _ = strconv.Atoi(m[1])
+		minor, _ = strconv.Atoi(m[2])
+
+		return major, minor, true
+	}
+
+	return 0, 0, false
+}
+
+// OwnedLines returns the lines that have a maintenance branch, as the first version of each line (e.g. v1.4.0).
+// Once the root branch has moved past them, tags on these lines belong to the maintenance branch.
+func (me *Config) OwnedLines(branches []string) Versions {
+	out := make(Versions, 0)
+
+	seen := map[string]bool{}
+	for _, branch := range branches {
+		major, minor, ok := me.LineFor(branch)
+		if !ok {
+			continue
+		}
+
+		ln := Version{Major: major, Minor: minor, Role: TagRoleRelease}
+		if seen[ln.String()] {
+			continue
+		}
+		seen[ln.String()] = true
+
+		out = append(out, ln)
+	}
+
+	return out
+}
+
+// ChangelogGroups returns the configured changelog groups, or the defaults
+func (me *Config) ChangelogGroups() []*ChangelogGroupConfig {
+	if me == nil || len(me.Changelog.Groups) == 0 {
+		return DefaultChangelogGroups
+	}
+	return me.Changelog.Groups
+}
+
+// PathGlobs returns every path glob in the config, the changed files are only needed if there are any
+func (me *Config) PathGlobs() []string {
+	if me == nil {
+		return nil
+	}
+	globs := append([]string{}, me.Skip.Paths...)
+	globs = append(globs, me.Paths.Include...)
+	return append(globs, me.Paths.Exclude...)
+}
+
+// PathsBump returns what happens if no changed file counts towards a bump: patch or skip
+func (me *Config) PathsBump() string {
+	if me == nil || me.Paths.Bump == "" {
+		return "patch"
+	}
+	return me.Paths.Bump
+}
+
+// CountsTowardsBump reports whether a changed file passes the include and exclude filters
+func (me *Config) CountsTowardsBump(file string) bool {
+	if me == nil {
+		return true
+	}
+
+	matches := func(p string) bool { return MatchGlob(p, file) }
+
+	if len(me.Paths.Include) > 0 && !slices.ContainsFunc(me.Paths.Include, matches) {
+		return false
+	}
+
+	return !slices.ContainsFunc(me.Paths.Exclude, matches)
+}
+
+// IsSkipPath reports whether a changed file matches one of the skip paths
+func (me *Config) IsSkipPath(file string) bool {
+	if me == nil {
+		return false
+	}
+	return slices.ContainsFunc(me.Skip.Paths, func(p string) bool { return MatchGlob(p, file) })
+}
@@ -0,0 +1,187 @@
+package simver_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+)
+
+func withDefaults(apply func(*simver.Config)) *simver.Config {
+	cfg := simver.DefaultConfig()
+	apply(cfg)
+	return cfg
+}
+
+func TestLoadConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected *simver.Config
+		err      bool
+	}{
+		{
+			name:     "missing file uses defaults",
+			expected: simver.DefaultConfig(),
+		},
+		{
+			name: "channels",
+			content: `
+channels:
+  - branch: release/*
+    name: rc
+    bump: major
+`,
+			expected: withDefaults(func(cfg *simver.Config) {
+				cfg.Channels = []*simver.ChannelConfig{{Branch: "release/*", Name: "rc", Bump: "major"}}
+			}),
+		},
+		{
+			name:    "calver scheme",
+			content: "scheme: calver\n",
+			expected: withDefaults(func(cfg *simver.Config) {
+				cfg.Scheme = "calver"
+			}),
+		},
+		{
+			name: "skip directives",
+			content: `
+skip:
+  labels: [docs]
+  paths: ["docs/**", "*.md"]
+`,
+			expected: withDefaults(func(cfg *simver.Config) {
+				cfg.Skip.Labels = []string{"docs"}
+				cfg.Skip.Paths = []string{"docs/**", "*.md"}
+			}),
+		},
+		{
+			name:    "invalid skip path",
+			content: "skip:\n  paths: [\"docs/[\"]\n",
+			err:     true,
+		},
+		{
+			name:    "skip paths with a patch bump",
+			content: "skip:\n  paths: [\"docs/**\"]\npaths:\n  exclude: [\".github/**\"]\n  bump: patch\n",
+			expected: withDefaults(func(cfg *simver.Config) {
+				cfg.Skip.Paths = []string{"docs/**"}
+				cfg.Paths.Exclude = []string{".github/**"}
+				cfg.Paths.Bump = "patch"
+			}),
+		},
+		{
+			name:    "invalid scheme",
+			content: "scheme: romver\n",
+			err:     true,
+		},
+		{
+			name: "invalid channel name",
+			content: `
+channels:
+  - branch: release/*
+    name: "r c"
+`,
+			err: true,
+		},
+		{
+			name: "invalid channel bump",
+			content: `
+channels:
+  - branch: release/*
+    name: rc
+    bump: sideways
+`,
+			err: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fls := afero.NewMemMapFs()
+			if tc.content != "" {
+				require.NoError(t, afero.WriteFile(fls, simver.ConfigFileName, []byte(tc.content), 0o644))
+			}
+
+			cfg, err := simver.LoadConfig(fls)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cfg)
+		})
+	}
+}
+
+func TestChannelFor(t *testing.T) {
+	cfg := &simver.Config{
+		Channels: []*simver.ChannelConfig{
+			{Branch: "release/*", Name: "rc"},
+			{Branch: "next", Name: "beta"},
+		},
+	}
+
+	ch, ok := cfg.ChannelFor("release/2.0")
+	require.True(t, ok)
+	assert.Equal(t, "rc", ch.Name)
+
+	ch, ok = cfg.ChannelFor("next")
+	require.True(t, ok)
+	assert.Equal(t, "beta", ch.Name)
+
+	_, ok = cfg.ChannelFor("main")
+	assert.False(t, ok)
+
+	var empty *simver.Config
+	_, ok = empty.ChannelFor("release/2.0")
+	assert.False(t, ok)
+}
+
+func TestLineFor(t *testing.T) {
+	cfg := &simver.Config{
+		Lines: []*simver.LineConfig{
+			{Branch: "release/*"},
+			{Branch: "lts", Line: "1.2"},
+		},
+	}
+
+	testCases := []struct {
+		branch string
+		major  int
+		minor  int
+		ok     bool
+	}{
+		{branch: "release/1.4", major: 1, minor: 4, ok: true},
+		{branch: "release/v2.10", major: 2, minor: 10, ok: true},
+		{branch: "release/next", ok: false},
+		{branch: "release/1.4.2", ok: false},
+		{branch: "lts", major: 1, minor: 2, ok: true},
+		{branch: "main", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.branch, func(t *testing.T) {
+			major, minor, ok := cfg.LineFor(tc.branch)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.major, major)
+			assert.Equal(t, tc.minor, minor)
+		})
+	}
+}
+
+func TestOwnedLines(t *testing.T) {
+	cfg := &simver.Config{
+		Lines: []*simver.LineConfig{{Branch: "release/*"}},
+	}
+
+	owned := cfg.OwnedLines([]string{"main", "release/1.8", "feature", "release/1.9", "release/v1.8"})
+
+	names := make([]string, 0, len(owned))
+	for _, v := range owned {
+		names = append(names, v.String())
+	}
+
+	assert.Equal(t, []string{"v1.8.0", "v1.9.0"}, names)
+}
@@ -0,0 +1,52 @@
+package simver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gitlab.com/tozd/go/errors"
+)
+
+const maxDockerTagLength = 128
+
+var dockerTagInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// DockerTag makes a version safe to use as an image tag. Docker forbids + and /, so build metadata is joined
+// with a dot (v1.2.3-pr4+5 becomes v1.2.3-pr4.5) and module prefixes with a dash (tools-v1.2.3).
+func DockerTag(version string) string {
+	tag := strings.ReplaceAll(version, "+", ".")
+	tag = dockerTagInvalidChars.ReplaceAllString(tag, "-")
+
+	if len(tag) > maxDockerTagLength {
+		tag = tag[:maxDockerTagLength]
+	}
+
+	return tag
+}
+
+// DockerTags returns the image tags for a version. Live releases on the root branch also get the floating
+// v1 and v1.2 aliases, and latest if the version has no module prefix.
+func DockerTags(version string, live bool) ([]string, error) {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return nil, errors.Errorf("deriving docker tags: %w", err)
+	}
+
+	tags := []string{DockerTag(version)}
+
+	if !live || v.Role != TagRoleRelease {
+		return tags, nil
+	}
+
+	tags = append(tags,
+		DockerTag(fmt.Sprintf("%sv%d", v.Prefix, v.Major)),
+		DockerTag(fmt.Sprintf("%sv%d.%d", v.Prefix, v.Major, v.Minor)),
+	)
+
+	if v.Prefix == "" {
+		tags = append(tags, "latest")
+	}
+
+	return tags, nil
+}
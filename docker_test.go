@@ -0,0 +1,37 @@
+package simver_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+)
+
+func TestDockerTags(t *testing.T) {
+	testCases := []struct {
+		version  string
+		live     bool
+		expected []string
+	}{
+		{version: "v1.2.3", live: true, expected: []string{"v1.2.3", "v1", "v1.2", "latest"}},
+		{version: "v1.2.3", live: false, expected: []string{"v1.2.3"}},
+		{version: "v1.2.3-pr4+5", live: true, expected: []string{"v1.2.3-pr4.5"}},
+		{version: "v1.2.3+build.7", live: true, expected: []string{"v1.2.3.build.7"}},
+		{version: "v2.0.0-rc.1", live: true, expected: []string{"v2.0.0-rc.1"}},
+		{version: "v2024.5.3", live: true, expected: []string{"v2024.5.3", "v2024", "v2024.5", "latest"}},
+		{version: "tools/v1.2.3", live: true, expected: []string{"tools-v1.2.3", "tools-v1", "tools-v1.2"}},
+		{version: "v1.4.0-dev.3+abc1234.dirty", live: false, expected: []string{"v1.4.0-dev.3.abc1234.dirty"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			got, err := simver.DockerTags(tc.version, tc.live)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+
+	_, err := simver.DockerTags("latest", true)
+	assert.Error(t, err)
+}
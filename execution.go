@@ -3,30 +3,68 @@ package simver
 import (
 	"context"
 	"fmt"
-	"regexp"
 	"slices"
-	"strconv"
 	"strings"
 
-	// exp sort
 	"github.com/rs/zerolog"
+	"gitlab.com/tozd/go/errors"
 	"golang.org/x/mod/semver"
 )
 
 type Execution interface {
 	PR() int
+	HeadBranch() string
+	BaseBranch() string
 	IsTargetingRoot() bool
 	IsMerge() bool
 	HeadCommitTags() Tags
 	HeadBranchTags() Tags
 	BaseBranchTags() Tags
 	RootBranchTags() Tags
+	PRTags() Tags
+	Branches() []string
+	HeadCommitMessage() string
+	Labels() []string
+	ChangedFiles() []string
 	ProvideRefs() RefProvider
 }
 
 const baseTag = "v0.1.0"
 
-func Calculate(ctx context.Context, ex Execution) *Calculation {
+var baseVersion = Version{Minor: 1, Role: TagRoleRelease} // baseTag
+
+func Calculate(ctx context.Context, ex Execution, cfg *Config) (*Calculation, error) {
+	format, err := cfg.TagFormat()
+	if err != nil {
+		return nil, errors.Errorf("building tag format: %w", err)
+	}
+
+	// the calculation only understands the default format, so custom tags are renamed on the way in
+	if !format.IsDefault() {
+		ex = &formatExecution{Execution: ex, format: format}
+	}
+
+	// pushes and merges to a channel branch produce prereleases, open prs against it are versioned as usual
+	if ch, ok := cfg.ChannelFor(ex.BaseBranch()); ok && (ex.PR() == 0 || ex.IsMerge()) {
+		calc, err := CalculateChannel(ctx, ex, ch, cfg.VersionScheme())
+		if err != nil {
+			return nil, err
+		}
+		calc.TagFormat = format
+		applySkipDirective(ctx, ex, cfg, calc)
+		return calc, nil
+	}
+
+	// anything on a maintenance branch only sees its own line, and can only be patched.
+	// everything else ignores the lines the root branch has left to a maintenance branch, even once their tags are merged forward.
+	major, minor, onLine := cfg.LineFor(ex.BaseBranch())
+	owned := Versions{}
+	if onLine {
+		ex = &lineExecution{Execution: ex, major: major, minor: minor}
+	} else if owned = linesBehindRoot(cfg.OwnedLines(ex.Branches()), ex.RootBranchTags()); len(owned) > 0 {
+		ex = &mainlineExecution{Execution: ex, owned: owned}
+	}
+
 	mrlt := MostRecentLiveTag(ex)
 
 	mrrt := MostRecentReservedTag(ex)
@@ -37,7 +75,35 @@ func Calculate(ctx context.Context, ex Execution) *Calculation {
 
 	mmrbn := MyMostRecentBuildNumber(ex)
 
-	return &Calculation{
+	scheme := cfg.VersionScheme()
+
+	// changes that only touch filtered out paths are downgraded to a patch
+	bump := PatchBump
+	if ex.IsTargetingRoot() && HasRelevantChanges(ex, cfg) {
+		bump = MinorBump
+	}
+
+	// reviewers can override the kind of change with a label, lines can only ever be patched
+	if override, ok := LabelBump(ex, cfg); ok && !onLine {
+		bump = override
+
+		// a version that was reserved before the label was added is too small if the label asks for a bigger change.
+		// a smaller change keeps its reservation, it was already taken for this pr.
+		if _, isSemVer := scheme.(SemVer); isSemVer && !mmrt.IsZero() {
+			if reserved, ok := ReservedBump(ex, mmrt); ok && bumpOrder[bump] > bumpOrder[reserved] {
+				mmrt = MMRT{}
+			}
+		}
+	}
+
+	nvt := skipOwnedLines(GetNextTag(ctx, scheme, bump, maxlr), owned)
+
+	// the first release of a line that has never been tagged
+	if onLine && mrlt.IsZero() && mrrt.IsZero() {
+		nvt = NVT{Major: major, Minor: minor, Role: TagRoleRelease}
+	}
+
+	calc := &Calculation{
 		IsMerged:          ex.IsMerge(),
 		MostRecentLiveTag: mrlt,
 		ForcePatch:        ForcePatch(ctx, ex, mmrt),
@@ -45,218 +111,548 @@ func Calculate(ctx context.Context, ex Execution) *Calculation {
 		MyMostRecentTag:   mmrt,
 		MyMostRecentBuild: mmrbn,
 		PR:                ex.PR(),
-		NextValidTag:      GetNextValidTag(ctx, ex.IsTargetingRoot(), maxlr),
+		NextValidTag:      nvt,
+		Scheme:            scheme,
+		TagFormat:         format,
+	}
+
+	if cfg != nil && cfg.PushBuilds && ex.PR() == 0 {
+		calc.PushBuild = int(MostRecentPushBuild(ex)) + 1
+	}
+
+	// a pr from a channel branch to root promotes the channel's prerelease to a clean release
+	if ch, ok := cfg.ChannelFor(ex.HeadBranch()); ok && !onLine && ex.PR() != 0 && ex.IsTargetingRoot() {
+		if promote, _ := MostRecentChannelTag(ex.HeadBranchTags(), ch.Name, mrlt); !promote.IsZero() {
+			calc.MyMostRecentTag = promote
+			calc.Skip = Skip(ctx, ex, promote)
+			calc.Promote = true
+		}
 	}
+
+	applySkipDirective(ctx, ex, cfg, calc)
+
+	return calc, nil
 }
 
-type MRLT string // most recent live tag
-type MRRT string // most recent reserved tag
-type NVT string  // next valid tag
-type MMRT string // my most recent tag
-type MMRBN int   // my most recent build number
-type MRT string  // my reserved tag
+// SkipDirective returns why a push or merge should not release a new version, or "" if it should
+func SkipDirective(ex Execution, cfg *Config) string {
+	if cfg == nil || (ex.PR() != 0 && !ex.IsMerge()) {
+		return ""
+	}
 
-type MAXLR string // max live or reserved tag
+	if msg := cfg.Skip.Message; msg != "" && strings.Contains(ex.HeadCommitMessage(), msg) {
+		return fmt.Sprintf("commit message contains %q", msg)
+	}
 
-type MAXMR string // max my reserved tag
+	if len(cfg.Skip.Labels) > 0 {
+		for _, label := range ex.Labels() {
+			if slices.Contains(cfg.Skip.Labels, label) {
+				return fmt.Sprintf("pr has label %q", label)
+			}
+		}
+	}
 
-type LST string // assumed last full decorated tag
+	if OnlySkipPathsChanged(ex, cfg) {
+		return "only skip paths changed"
+	}
 
-func MaxLiveOrReservedTag(mrlt MRLT, mrrt MRRT) MAXLR {
-	return MAXLR(Max(mrlt, mrrt))
+	if cfg.PathsBump() == "skip" && !HasRelevantChanges(ex, cfg) {
+		return "no changed files count towards a bump"
+	}
+
+	return ""
 }
 
-func MaxMyOrReservedTag(mrrt MRRT, mmrt MMRT) MAXMR {
-	return MAXMR(Max(mrrt, mmrt))
+// LabelBump returns the kind of change the pr labels ask for. If there are several, the biggest one wins.
+func LabelBump(ex Execution, cfg *Config) (Bump, bool) {
+	if cfg == nil || (cfg.BumpLabels.Patch == "" && cfg.BumpLabels.Minor == "" && cfg.BumpLabels.Major == "") {
+		return "", false
+	}
+
+	found := Bump("")
+	for _, label := range ex.Labels() {
+		switch label {
+		case "":
+		case cfg.BumpLabels.Major:
+			found = MajorBump
+		case cfg.BumpLabels.Minor:
+			if found != MajorBump {
+				found = MinorBump
+			}
+		case cfg.BumpLabels.Patch:
+			if found == "" {
+				found = PatchBump
+			}
+		}
+	}
+
+	return found, found != ""
 }
 
-func BumpPatch[S ~string](arg S) S {
+// HasRelevantChanges reports whether any of the changed files count towards a bump. Without path filters,
+// or if the changed files are unknown, everything counts.
+func HasRelevantChanges(ex Execution, cfg *Config) bool {
+	if len(cfg.PathGlobs()) == 0 {
+		return true
+	}
+
+	files := ex.ChangedFiles()
+	if len(files) == 0 {
+		return true
+	}
 
-	maj := semver.MajorMinor(string(arg))
-	patch := strings.Split(strings.TrimPrefix(string(arg), maj), "-")[0]
+	return slices.ContainsFunc(files, cfg.CountsTowardsBump)
+}
 
-	patch = strings.TrimPrefix(patch, ".")
+// ReleasesOnRoot reports whether the releases of ex are new versions of the root branch. A push is simulated
+// as a pr into its own branch, so pushes to maintenance lines and channels are ruled out by their branch.
+func ReleasesOnRoot(ex Execution, cfg *Config) bool {
+	if !ex.IsTargetingRoot() {
+		return false
+	}
 
-	if patch == "" {
-		patch = "0"
+	if _, _, ok := cfg.LineFor(ex.BaseBranch()); ok {
+		return false
 	}
 
-	patchnum, err := strconv.Atoi(patch)
-	if err != nil {
-		panic("patchnum is not a number somehow: " + patch)
+	if _, ok := cfg.ChannelFor(ex.BaseBranch()); ok {
+		return false
 	}
 
-	patchnum++
+	return true
+}
+
+// OnlySkipPathsChanged reports whether every changed file matches the skip paths. If the changed files are
+// unknown, nothing is skipped.
+func OnlySkipPathsChanged(ex Execution, cfg *Config) bool {
+	if len(cfg.Skip.Paths) == 0 {
+		return false
+	}
 
-	return S(fmt.Sprintf("%s.%d", maj, patchnum))
+	files := ex.ChangedFiles()
+	if len(files) == 0 {
+		return false
+	}
 
+	for _, file := range files {
+		if !cfg.IsSkipPath(file) {
+			return false
+		}
+	}
+
+	return true
 }
 
-func Skip(ctx context.Context, ee Execution, mmrt MMRT) bool {
-	reg := regexp.MustCompile(fmt.Sprintf(`^%s$`, mmrt))
+// applySkipDirective skips the release if asked to. The commit is still tagged with a build of the
+// current version (like v1.2.3+build.4), so anything waiting on a tag for it can move on.
+func applySkipDirective(ctx context.Context, ex Execution, cfg *Config, calc *Calculation) {
+	if calc.Skip {
+		return
+	}
 
-	// head commit tags matching mmrt
-	hct := ee.HeadCommitTags().SemversMatching(func(s string) bool {
-		return reg.MatchString(s)
-	})
+	reason := SkipDirective(ex, cfg)
+	if reason == "" {
+		return
+	}
 
-	return len(hct) > 0
+	calc.Skip = true
+	calc.SkipReason = reason
+	calc.PushBuild = 0
+
+	// an earlier run for this commit already left the build tag
+	if len(ex.HeadCommitTags().Versions().WithPrefix("").WithRole(TagRoleBuild)) == 0 {
+		calc.PushBuild = int(MostRecentPushBuild(ex)) + 1
+	}
+
+	zerolog.Ctx(ctx).Info().Str("reason", reason).Int("build", calc.PushBuild).Msg("skipping release")
 }
 
-func ForcePatch(ctx context.Context, ee Execution, mmrt MMRT) bool {
-	// if our head branch has a
-	reg := regexp.MustCompile(fmt.Sprintf(`^%s$`, mmrt))
+// CalculateChannel calculates the next prerelease for a push or merge to a channel branch.
+// The channel keeps its version until it is promoted, otherwise it starts a new one and reserves it.
+func CalculateChannel(ctx context.Context, ex Execution, ch *ChannelConfig, scheme Scheme) (*Calculation, error) {
+	scheme = schemeOrDefault(scheme)
 
-	// head branch tags matching mmrt
-	hbt := ee.HeadBranchTags().SemversMatching(func(s string) bool {
-		return reg.MatchString(s)
-	})
+	// the channel branch might not have seen the latest releases on root yet
+	mrlt := MostRecentLiveTag(ex)
+	if root := MostRecentLiveTag(&rootAsBase{ex}); root.Compare(mrlt) > 0 {
+		mrlt = root
+	}
+
+	mrrt := MostRecentReservedTag(ex)
+
+	maxlr := MaxLiveOrReservedTag(mrlt, mrrt)
+
+	version, build := MostRecentChannelTag(ex.BaseBranchTags(), ch.Name, mrlt)
+
+	bump := MinorBump
+	if ch.Bump != "" {
+		bump = Bump(ch.Bump)
+	}
+
+	next := scheme.Next(maxlr, bump)
+
+	// if the head commit already has a prerelease on this channel, it has already been tagged
+	skip := len(ex.HeadCommitTags().Versions().WithPrefix("").Filter(func(v Version) bool {
+		return v.Role == TagRoleChannel && v.Prerelease[0] == ch.Name
+	})) > 0
 
-	return len(hbt) > 0
+	zerolog.Ctx(ctx).Debug().
+		Str("channel", ch.Name).
+		Stringer("mrlt", mrlt).
+		Stringer("maxlr", maxlr).
+		Stringer("version", version).
+		Int("build", build).
+		Stringer("next", next).
+		Msg("calculated channel")
+
+	return &Calculation{
+		IsMerged:          ex.IsMerge(),
+		MostRecentLiveTag: mrlt,
+		Skip:              skip,
+		MyMostRecentTag:   version,
+		MyMostRecentBuild: MMRBN(build),
+		PR:                ex.PR(),
+		NextValidTag:      next,
+		Channel:           ch.Name,
+		Scheme:            scheme,
+	}, nil
 }
 
-func MostRecentLiveTag(e Execution) MRLT {
-	reg := regexp.MustCompile(`^v\d+\.\d+\.\d+$`)
-	highest := e.BaseBranchTags().SemversMatching(func(s string) bool {
-		return reg.MatchString(s)
+// MostRecentChannelTag finds the highest version that has prereleases on the given channel (like v2.0.0-rc.3)
+// and has not been released yet, along with its highest prerelease number
+func MostRecentChannelTag(tags Tags, channel string, mrlt MRLT) (MMRT, int) {
+	prereleases := tags.Versions().WithPrefix("").WithRole(TagRoleChannel).Filter(func(v Version) bool {
+		return v.Prerelease[0] == channel && (mrlt.IsZero() || v.Core().Compare(mrlt) > 0)
 	})
 
-	if len(highest) == 0 {
-		return ""
+	highest, ok := prereleases.Highest()
+	if !ok {
+		return MMRT{}, 0
+	}
+
+	core := highest.Core()
+
+	build := 0
+	for _, v := range prereleases {
+		if v.Core().Compare(core) == 0 {
+			build = max(build, v.BuildNumber)
+		}
 	}
 
-	return MRLT(strings.Split(semver.Canonical(highest[len(highest)-1]), "-")[0])
+	return core, build
 }
 
-func MyMostRecentTag(e Execution) MMRT {
-	reg := regexp.MustCompile(`^v\d+\.\d+\.\d+.*$`)
-	highest := e.HeadBranchTags().SemversMatching(func(s string) bool {
-		if strings.Contains(s, "-reserved") || strings.Contains(s, "-base") {
-			return false
+// rootAsBase exposes the root branch tags as base branch tags, so the live tag helpers can be used on root
+type rootAsBase struct {
+	Execution
+}
+
+func (me *rootAsBase) BaseBranchTags() Tags {
+	return me.Execution.RootBranchTags()
+}
+
+// formatExecution renames tags of a custom format to the default format
+type formatExecution struct {
+	Execution
+	format *TagFormat
+}
+
+func (me *formatExecution) canonical(tags Tags) Tags {
+	out := make(Tags, 0, len(tags))
+	for _, tag := range tags {
+		prefix, name := "", tag.Name
+		if idx := strings.LastIndex(name, "/"); idx >= 0 {
+			prefix, name = name[:idx+1], name[idx+1:]
 		}
-		return reg.MatchString(s)
-	})
+		out = append(out, Tag{Name: prefix + me.format.Canonical(name), Ref: tag.Ref})
+	}
+	return out
+}
 
-	if len(highest) == 0 {
-		return ""
+func (me *formatExecution) HeadCommitTags() Tags { return me.canonical(me.Execution.HeadCommitTags()) }
+func (me *formatExecution) HeadBranchTags() Tags { return me.canonical(me.Execution.HeadBranchTags()) }
+func (me *formatExecution) BaseBranchTags() Tags { return me.canonical(me.Execution.BaseBranchTags()) }
+func (me *formatExecution) RootBranchTags() Tags { return me.canonical(me.Execution.RootBranchTags()) }
+func (me *formatExecution) PRTags() Tags         { return me.canonical(me.Execution.PRTags()) }
+
+// lineExecution limits every tag set to a single major.minor line. Lines are never the root branch,
+// so the next version is always a patch.
+type lineExecution struct {
+	Execution
+	major int
+	minor int
+}
+
+func (me *lineExecution) onLine(tags Tags) Tags {
+	out := make(Tags, 0, len(tags))
+	for _, tag := range tags {
+		v, err := ParseVersion(tag.Name)
+		if err != nil || v.Major != me.major || v.Minor != me.minor {
+			continue
+		}
+		out = append(out, tag)
 	}
+	return out
+}
 
-	return MMRT(strings.Split(semver.Canonical(highest[len(highest)-1]), "-")[0])
+func (me *lineExecution) IsTargetingRoot() bool { return false }
+func (me *lineExecution) HeadCommitTags() Tags  { return me.onLine(me.Execution.HeadCommitTags()) }
+func (me *lineExecution) HeadBranchTags() Tags  { return me.onLine(me.Execution.HeadBranchTags()) }
+func (me *lineExecution) BaseBranchTags() Tags  { return me.onLine(me.Execution.BaseBranchTags()) }
+func (me *lineExecution) RootBranchTags() Tags  { return me.onLine(me.Execution.RootBranchTags()) }
+func (me *lineExecution) PRTags() Tags          { return me.onLine(me.Execution.PRTags()) }
+
+// mainlineExecution hides the tags of lines that are owned by a maintenance branch, so that hotfixes
+// merged forward (like v1.8.3 on a main that is at v1.9.x) do not affect the versions of the root line
+type mainlineExecution struct {
+	Execution
+	owned Versions
 }
 
-func MostRecentReservedTag(e Execution) MRRT {
-	reg := regexp.MustCompile(`^v\d+\.\d+\.\d+-reserved$`)
-	highest := e.RootBranchTags().SemversMatching(func(s string) bool {
-		return reg.MatchString(s)
+func (me *mainlineExecution) offLines(tags Tags) Tags {
+	out := make(Tags, 0, len(tags))
+	for _, tag := range tags {
+		if v, err := ParseVersion(tag.Name); err == nil && isOwnedLine(v, me.owned) {
+			continue
+		}
+		out = append(out, tag)
+	}
+	return out
+}
+
+func (me *mainlineExecution) HeadCommitTags() Tags { return me.offLines(me.Execution.HeadCommitTags()) }
+func (me *mainlineExecution) HeadBranchTags() Tags { return me.offLines(me.Execution.HeadBranchTags()) }
+func (me *mainlineExecution) BaseBranchTags() Tags { return me.offLines(me.Execution.BaseBranchTags()) }
+func (me *mainlineExecution) RootBranchTags() Tags { return me.offLines(me.Execution.RootBranchTags()) }
+func (me *mainlineExecution) PRTags() Tags         { return me.offLines(me.Execution.PRTags()) }
+
+func isOwnedLine(v Version, owned Versions) bool {
+	if v.Prefix != "" {
+		return false
+	}
+	for _, ln := range owned {
+		if v.Major == ln.Major && v.Minor == ln.Minor {
+			return true
+		}
+	}
+	return false
+}
+
+// linesBehindRoot keeps the lines the root branch has moved past, going by the releases reachable from it.
+// A maintenance branch created for the line the root branch is still on does not take that line away from it.
+func linesBehindRoot(lines Versions, root Tags) Versions {
+	if len(lines) == 0 {
+		return lines
+	}
+
+	current, ok := root.Versions().WithPrefix("").WithRole(TagRoleRelease).Highest()
+	if !ok {
+		return Versions{}
+	}
+
+	return lines.Filter(func(ln Version) bool {
+		return ln.Major < current.Major || (ln.Major == current.Major && ln.Minor < current.Minor)
 	})
+}
 
-	if len(highest) == 0 {
-		return ""
+// skipOwnedLines moves nvt past any lines that are owned by a maintenance branch
+func skipOwnedLines(nvt NVT, owned Versions) NVT {
+	for isOwnedLine(nvt, owned) {
+		nvt = nvt.BumpMinor()
 	}
 
-	return MRRT(strings.Split(semver.Canonical(highest[len(highest)-1]), "-")[0])
+	return nvt
 }
 
-func MyMostRecentBuildNumber(e Execution) MMRBN {
-	reg := regexp.MustCompile(fmt.Sprintf(`^.*-pr%d\+\d+$`, e.PR()))
-	highest := e.HeadBranchTags().SemversMatching(func(s string) bool {
-		return reg.MatchString(s)
-	})
+// the versions a calculation is made of are parsed once, the zero Version means there is none
+type MRLT = Version // most recent live tag
+type MRRT = Version // most recent reserved tag
+type NVT = Version  // next valid tag
+type MMRT = Version // my most recent tag
+type MMRBN int      // my most recent build number
+type MRPB int       // most recent push build number
+type MRT = Version  // my reserved tag
+
+type MAXLR = Version // max live or reserved tag
+
+type MAXMR = Version // max my reserved tag
+
+type LST string // assumed last full decorated tag
+
+func MaxLiveOrReservedTag(mrlt MRLT, mrrt MRRT) MAXLR {
+	return Max(mrlt, mrrt)
+}
+
+func MaxMyOrReservedTag(mrrt MRRT, mmrt MMRT) MAXMR {
+	return Max(mrrt, mmrt)
+}
 
-	if len(highest) == 0 {
-		return 0
+// BumpPatch returns the next patch release after arg. Shorthands like v1.2 are accepted here.
+func BumpPatch[S ~string](arg S) (S, error) {
+	v, err := ParseVersion(semver.Canonical(string(arg)))
+	if err != nil {
+		return "", errors.Errorf("bumping patch: %w", err)
 	}
 
-	slices.SortFunc(highest, func(a, b string) int {
-		// because we know the regex matches, we know the split will be len 2
-		// and the second element will be a valid number
-		ai, _ := strconv.Atoi(strings.Split(a, "+")[1])
-		bi, _ := strconv.Atoi(strings.Split(b, "+")[1])
-		return bi - ai
-	})
+	return S(v.BumpPatch().String()), nil
+}
 
-	high := highest[0]
+// hasTagNamed reports whether any of the tags is exactly named mmrt
+func hasTagNamed(tags Tags, mmrt MMRT) bool {
+	if mmrt.IsZero() {
+		return false
+	}
 
-	// get the build number
-	split := strings.Split(high, "+")
-	if len(split) != 2 {
-		return 0
+	name := mmrt.String()
+	for _, tag := range tags {
+		if tag.Name == name {
+			return true
+		}
 	}
-	n, err := strconv.Atoi(split[1])
-	if err != nil {
-		return 0
+
+	return false
+}
+
+func Skip(ctx context.Context, ee Execution, mmrt MMRT) bool {
+	// head commit tags matching mmrt
+	return hasTagNamed(ee.HeadCommitTags(), mmrt)
+}
+
+func ForcePatch(ctx context.Context, ee Execution, mmrt MMRT) bool {
+	// head branch tags matching mmrt
+	return hasTagNamed(ee.HeadBranchTags(), mmrt)
+}
+
+func MostRecentLiveTag(e Execution) MRLT {
+	highest, ok := e.BaseBranchTags().Versions().WithPrefix("").WithRole(TagRoleRelease).Highest()
+	if !ok {
+		return MRLT{}
 	}
 
-	return MMRBN(n)
+	return highest.Core()
 }
 
-func Max[A ~string, B ~string](a A, b B) string {
-	var max string
+func MyMostRecentTag(e Execution) MMRT {
+	pr := e.PR()
+
+	// after a force push the head branch might no longer contain the tags from our
+	// previous builds, so we also look at the tags that belong to this pr by name
+	tags := append(e.HeadBranchTags().Copy(), e.PRTags()...)
 
-	if a == "" || b == "" {
-		if a != "" {
-			max = string(a)
-		} else if b != "" {
-			max = string(b)
-		} else {
-			max = baseTag
+	highest, ok := tags.Versions().WithPrefix("").Filter(func(v Version) bool {
+		if v.Role == TagRoleReserved || v.Role == TagRoleInvalid {
+			return false
 		}
-	} else {
-		// only compare if both exist
-		if semver.Compare(string(b), string(a)) > 0 {
-			max = string(b)
-		} else {
-			max = string(a)
+		// the base commit of another pr (like a shared root commit) carries its base marker and builds,
+		// which are that pr's reservation, not ours
+		if (v.Role == TagRoleBase || v.Role == TagRolePR) && v.PR != pr {
+			return false
 		}
+		// other tools' base markers (like v1.2.3-base) are never ours
+		return len(v.Prerelease) == 0 || v.Prerelease[0] != "base"
+	}).Highest()
+	if !ok {
+		return MMRT{}
 	}
 
-	return max
+	return highest.Core()
 }
 
-func GetNextValidTag(ctx context.Context, minor bool, maxt MAXLR) NVT {
+// ReservedBump returns the kind of change mmrt was reserved for, which is how far it is from the highest
+// release or reservation below it. False if there is nothing below it.
+func ReservedBump(e Execution, mmrt MMRT) (Bump, bool) {
+	tags := append(e.RootBranchTags().Copy(), e.BaseBranchTags()...)
+
+	below, ok := tags.Versions().WithPrefix("").Filter(func(v Version) bool {
+		return (v.Role == TagRoleRelease || v.Role == TagRoleReserved) && v.Core().Compare(mmrt.Core()) < 0
+	}).Highest()
+	if !ok {
+		return "", false
+	}
+
+	return bumpBetween(below, mmrt), true
+}
 
-	var max string
-	if maxt == "" {
-		max = baseTag
-	} else {
-		max = string(maxt)
+// MostRecentReservedTag is the highest reservation on the root branch. With hidden storage, reservations are
+// refs/simver/reserved/ refs, which the tag reader returns as reserved tags like any other.
+func MostRecentReservedTag(e Execution) MRRT {
+	highest, ok := e.RootBranchTags().Versions().WithPrefix("").WithRole(TagRoleReserved).Highest()
+	if !ok {
+		return MRRT{}
 	}
 
-	maj := semver.Major(max) + "."
+	return highest.Core()
+}
 
-	majmin := semver.MajorMinor(max)
+func MyMostRecentBuildNumber(e Execution) MMRBN {
+	pr := e.PR()
 
-	patch := strings.Split(strings.TrimPrefix(max, majmin+"."), "-")[0]
+	// orphaned build tags (from before a force push) still count towards the sequence
+	tags := append(e.HeadBranchTags().Copy(), e.PRTags()...)
 
-	min := strings.TrimPrefix(majmin, maj)
+	builds := tags.Versions().WithPrefix("").WithRole(TagRolePR).Filter(func(v Version) bool {
+		return v.PR == pr
+	})
 
-	minornum, err := strconv.Atoi(min)
-	if err != nil {
-		panic("minornum is not a number somehow: " + min)
+	max := 0
+	for _, v := range builds {
+		if v.BuildNumber > max {
+			max = v.BuildNumber
+		}
 	}
 
-	patchnum, err := strconv.Atoi(patch)
-	if err != nil {
-		panic("patchnum is not a number somehow: " + patch)
+	return MMRBN(max)
+}
+
+// MostRecentPushBuild is the highest push build number on the base branch, across all versions
+func MostRecentPushBuild(e Execution) MRPB {
+	max := 0
+	for _, v := range e.BaseBranchTags().Versions().WithPrefix("").WithRole(TagRoleBuild) {
+		if v.BuildNumber > max {
+			max = v.BuildNumber
+		}
+	}
+
+	return MRPB(max)
+}
+
+// Max returns the higher of two versions, ignoring zero values.
+// If both are zero, the base tag is returned.
+func Max(a, b Version) Version {
+	switch {
+	case a.IsZero() && b.IsZero():
+		return baseVersion
+	case a.IsZero():
+		return b
+	case b.IsZero():
+		return a
+	case b.Compare(a) > 0:
+		return b
+	default:
+		return a
 	}
+}
 
+// GetNextValidTag returns the next minor version after maxt for changes targeting root, the next patch otherwise
+func GetNextValidTag(ctx context.Context, scheme Scheme, minor bool, maxt MAXLR) NVT {
 	if minor {
-		minornum++
-		patchnum = 0
-	} else {
-		patchnum++
+		return GetNextTag(ctx, scheme, MinorBump, maxt)
+	}
+	return GetNextTag(ctx, scheme, PatchBump, maxt)
+}
+
+func GetNextTag(ctx context.Context, scheme Scheme, bump Bump, maxt MAXLR) NVT {
+	if maxt.IsZero() {
+		maxt = baseVersion
 	}
 
+	next := schemeOrDefault(scheme).Next(maxt, bump)
+
 	zerolog.Ctx(ctx).Debug().
-		Str("max", max).
-		Str("maj", maj).
-		Str("majmin", majmin).
-		Str("patch", patch).
-		Str("min", min).
-		Int("minornum", minornum).
-		Int("patchnum", patchnum).
+		Stringer("max", maxt).
+		Str("bump", string(bump)).
+		Stringer("next", next).
 		Msg("calculated next valid tag")
 
-	return NVT(fmt.Sprintf("%s.%d.%d", semver.Major(max), minornum, patchnum))
-
+	return next
 }
@@ -5,10 +5,17 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/walteh/simver"
 	"github.com/walteh/simver/gen/mockery"
 )
 
+// ver parses a version for test tables, anything that is not a version is the zero Version
+func ver(name string) simver.Version {
+	v, _ := simver.ParseVersion(name)
+	return v
+}
+
 func TestMrlt(t *testing.T) {
 	testCases := []struct {
 		name         string
@@ -18,17 +25,17 @@ func TestMrlt(t *testing.T) {
 		{
 			name:         "Valid MRLT",
 			tags:         simver.Tags{simver.Tag{Name: "v1.2.3"}, simver.Tag{Name: "v1.2.4"}},
-			expectedMrlt: "v1.2.4",
+			expectedMrlt: ver("v1.2.4"),
 		},
 		{
 			name:         "No MRLT",
 			tags:         simver.Tags{},
-			expectedMrlt: "",
+			expectedMrlt: ver(""),
 		},
 		{
 			name:         "Invalid Semver Format",
 			tags:         simver.Tags{simver.Tag{Name: "v1.2"}, simver.Tag{Name: "v1.2.x"}},
-			expectedMrlt: "",
+			expectedMrlt: ver(""),
 		},
 	}
 
@@ -48,39 +55,48 @@ func TestMmrt(t *testing.T) {
 		name         string
 		prNum        int
 		tags         simver.Tags
+		prTags       simver.Tags
 		expectedMmrt simver.MMRT
 	}{
 		{
 			name:         "Valid MMRT",
 			prNum:        1,
 			tags:         simver.Tags{simver.Tag{Name: "v1.2.3-pr1+base"}},
-			expectedMmrt: "v1.2.3",
+			expectedMmrt: ver("v1.2.3"),
 		},
 		{
 			name:         "Invalid MMRT",
 			prNum:        3,
 			tags:         simver.Tags{simver.Tag{Name: "v1.2.3-pr3+0"}},
-			expectedMmrt: "v1.2.3",
+			expectedMmrt: ver("v1.2.3"),
 		},
 		{
 			name:         "No MMRT",
 			prNum:        2,
 			tags:         simver.Tags{},
-			expectedMmrt: "",
+			expectedMmrt: ver(""),
 		},
 		{
 			name:         "Non-Matching PR Number",
 			prNum:        3,
-			tags:         simver.Tags{simver.Tag{Name: "v1.2.3-pr1+base"}},
-			expectedMmrt: "v1.2.3",
+			tags:         simver.Tags{simver.Tag{Name: "v1.2.3-pr1+base"}, simver.Tag{Name: "v1.2.3-pr1+2"}},
+			expectedMmrt: ver(""),
+		},
+		{
+			name:         "Orphaned PR Tags After Force Push",
+			prNum:        4,
+			tags:         simver.Tags{simver.Tag{Name: "v1.2.3"}},
+			prTags:       simver.Tags{simver.Tag{Name: "v1.2.4-pr4+base"}, simver.Tag{Name: "v1.2.4-pr4+2"}},
+			expectedMmrt: ver("v1.2.4"),
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			mockExec := new(mockery.MockExecution_simver)
-			// mockExec.EXPECT().PR().Return(tc.prNum)
+			mockExec.EXPECT().PR().Return(tc.prNum)
 			mockExec.EXPECT().HeadBranchTags().Return(tc.tags)
+			mockExec.EXPECT().PRTags().Return(tc.prTags)
 			result := simver.MyMostRecentTag(mockExec)
 			mockExec.AssertExpectations(t)
 			assert.Equal(t, tc.expectedMmrt, result)
@@ -97,17 +113,17 @@ func TestMrrt(t *testing.T) {
 		{
 			name:         "Valid MRRT",
 			tags:         simver.Tags{simver.Tag{Name: "v1.2.3-reserved"}},
-			expectedMrrt: "v1.2.3",
+			expectedMrrt: ver("v1.2.3"),
 		},
 		{
 			name:         "No MRRT",
 			tags:         simver.Tags{},
-			expectedMrrt: "",
+			expectedMrrt: ver(""),
 		},
 		{
 			name:         "Invalid Reserved Tag Format",
 			tags:         simver.Tags{simver.Tag{Name: "v1.2-reserved"}},
-			expectedMrrt: "",
+			expectedMrrt: ver(""),
 		},
 	}
 
@@ -172,8 +188,8 @@ func TestMax(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			res := simver.Max(tc.a, tc.b)
-			assert.Equal(t, tc.expected, res)
+			res := simver.Max(ver(tc.a), ver(tc.b))
+			assert.Equal(t, tc.expected, res.String())
 		})
 	}
 }
@@ -187,40 +203,40 @@ func TestNvt(t *testing.T) {
 	}{
 		{
 			name:        "normal",
-			max:         "v1.2.4-reserved",
+			max:         ver("v1.2.4-reserved"),
 			minor:       false,
-			expectedNvt: "v1.2.5",
+			expectedNvt: ver("v1.2.5"),
 		},
 		{
 			name:        "minor",
-			max:         "v1.2.4-reserved",
+			max:         ver("v1.2.4-reserved"),
 			minor:       true,
-			expectedNvt: "v1.3.0",
+			expectedNvt: ver("v1.3.0"),
 		},
 		{
 			name:        "no mrlt",
-			max:         "v1.2.4-reserved",
+			max:         ver("v1.2.4-reserved"),
 			minor:       false,
-			expectedNvt: "v1.2.5",
+			expectedNvt: ver("v1.2.5"),
 		},
 		{
 			name:        "no mrrt",
-			max:         "v1.2.3",
+			max:         ver("v1.2.3"),
 			minor:       false,
-			expectedNvt: "v1.2.4",
+			expectedNvt: ver("v1.2.4"),
 		},
 		{
 			name: "no mrlt or mrrt",
-			max:  "",
+			max:  ver(""),
 
 			minor:       false,
-			expectedNvt: "v0.1.1", // base tag is v0.1.0
+			expectedNvt: ver("v0.1.1"), // base tag is v0.1.0
 		},
 		{
 			name:        "invalid mrlt",
-			max:         "v1.2.4-reserved",
+			max:         ver("v1.2.4-reserved"),
 			minor:       false,
-			expectedNvt: "v1.2.5",
+			expectedNvt: ver("v1.2.5"),
 		},
 	}
 
@@ -229,7 +245,7 @@ func TestNvt(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 
-			result := simver.GetNextValidTag(ctx, tc.minor, tc.max)
+			result := simver.GetNextValidTag(ctx, simver.SemVer{}, tc.minor, tc.max)
 			assert.Equal(t, tc.expectedNvt, result)
 		})
 	}
@@ -249,6 +265,7 @@ func TestNewTags(t *testing.T) {
 		headBranchTags  simver.Tags
 		rootBranchTags  simver.Tags
 		headCommitTags  simver.Tags
+		prTags          simver.Tags
 		pr              int
 		isMerge         bool
 		isTargetingRoot bool
@@ -427,6 +444,36 @@ func TestNewTags(t *testing.T) {
 				simver.Tag{Name: "v1.2.4-pr2+6", Ref: head_ref},
 			},
 		},
+		{
+			name:            "force push orphaned previous builds",
+			baseBranchTags:  simver.Tags{simver.Tag{Name: "v1.2.3"}},
+			headBranchTags:  simver.Tags{},
+			headCommitTags:  simver.Tags{},
+			rootBranchTags:  simver.Tags{simver.Tag{Name: "v1.2.4-reserved"}},
+			prTags:          simver.Tags{simver.Tag{Name: "v1.2.4-pr5+base"}, simver.Tag{Name: "v1.2.4-pr5+1"}, simver.Tag{Name: "v1.2.4-pr5+2"}},
+			pr:              5,
+			isMerge:         false,
+			isTargetingRoot: false,
+			expectedTags: simver.Tags{
+				// the head branch no longer sees the old build tags, but the pr still owns v1.2.4
+				// so we continue the build number sequence instead of reserving a new version
+				simver.Tag{Name: "v1.2.4-pr5+3", Ref: head_ref},
+			},
+		},
+		{
+			name:            "force push with some builds still reachable",
+			baseBranchTags:  simver.Tags{simver.Tag{Name: "v1.2.3"}},
+			headBranchTags:  simver.Tags{simver.Tag{Name: "v1.2.4-pr5+base"}, simver.Tag{Name: "v1.2.4-pr5+1"}},
+			headCommitTags:  simver.Tags{},
+			rootBranchTags:  simver.Tags{simver.Tag{Name: "v1.2.4-reserved"}},
+			prTags:          simver.Tags{simver.Tag{Name: "v1.2.4-pr5+base"}, simver.Tag{Name: "v1.2.4-pr5+1"}, simver.Tag{Name: "v1.2.4-pr5+7"}},
+			pr:              5,
+			isMerge:         false,
+			isTargetingRoot: false,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.2.4-pr5+8", Ref: head_ref},
+			},
+		},
 		{
 			name:            "reserved tag already exists",
 			baseBranchTags:  simver.Tags{simver.Tag{Name: "v1.2.3"}},
@@ -678,17 +725,25 @@ func TestNewTags(t *testing.T) {
 			mockExec.EXPECT().IsTargetingRoot().Return(tc.isTargetingRoot)
 			mockExec.EXPECT().IsMerge().Return(tc.isMerge)
 			mockExec.EXPECT().RootBranchTags().Return(tc.rootBranchTags)
+			mockExec.EXPECT().PRTags().Return(tc.prTags)
+			mockExec.EXPECT().Branches().Return(nil)
+			mockExec.EXPECT().HeadBranch().Return("feature")
+			mockExec.EXPECT().BaseBranch().Return("main")
 			mockExec.EXPECT().IsDirty().Return(false)
 			mockExec.EXPECT().IsLocal().Return(false)
 
-			got := simver.Calculate(ctx, mockExec).
-				CalculateNewTagsRaw(ctx).
-				ApplyRefs(&simver.BasicRefProvider{
-					HeadRef:  head_ref,
-					BaseRef:  base_ref,
-					RootRef:  root_ref,
-					MergeRef: merge_ref,
-				})
+			calc, err := simver.Calculate(ctx, mockExec, nil)
+			require.NoError(t, err)
+
+			out, err := calc.CalculateNewTagsRaw(ctx)
+			require.NoError(t, err)
+
+			got := out.ApplyRefs(&simver.BasicRefProvider{
+				HeadRef:  head_ref,
+				BaseRef:  base_ref,
+				RootRef:  root_ref,
+				MergeRef: merge_ref,
+			})
 
 			assert.ElementsMatch(t, tc.expectedTags, got)
 		})
@@ -699,37 +754,685 @@ func TestTagString_BumpPatch(t *testing.T) {
 		name     string
 		input    string
 		expected string
-		panic    bool
+		err      bool
 	}{
 		{
 			name:     "BumpPatch with patch version",
 			input:    "v1.2.3",
 			expected: "v1.2.4",
-			panic:    false,
+			err:      false,
 		},
 		{
 			name:     "BumpPatch with no patch version",
 			input:    "v1.2",
 			expected: "v1.2.1",
-			panic:    false,
+			err:      false,
 		},
 		{
 			name:  "BumpPatch with invalid patch version",
 			input: "v1.2.x",
-			panic: true,
+			err:   true,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			if tc.panic {
-				assert.Panics(t, func() {
-					simver.BumpPatch(tc.input)
-				})
+			result, err := simver.BumpPatch(tc.input)
+			if tc.err {
+				assert.Error(t, err)
 				return
 			}
-			result := simver.BumpPatch(tc.input)
+			require.NoError(t, err)
 			assert.Equal(t, tc.expected, result)
 		})
 	}
 }
+
+func TestLineTags(t *testing.T) {
+	cfg := &simver.Config{
+		Lines: []*simver.LineConfig{
+			{Branch: "release/*"},
+			{Branch: "lts", Line: "1.2"},
+		},
+	}
+
+	mainTags := simver.Tags{
+		simver.Tag{Name: "v1.9.0"},
+		simver.Tag{Name: "v1.10.0-reserved"},
+	}
+
+	testCases := []struct {
+		name           string
+		headBranch     string
+		baseBranch     string
+		baseBranchTags simver.Tags
+		headBranchTags simver.Tags
+		rootBranchTags simver.Tags
+		branches       []string
+		pr             int
+		isMerge        bool
+		expectedTags   simver.Tags
+	}{
+		{
+			name:           "push to a maintenance branch patches its line",
+			headBranch:     "release/1.4",
+			baseBranch:     "release/1.4",
+			baseBranchTags: simver.Tags{simver.Tag{Name: "v1.4.2"}, simver.Tag{Name: "v1.3.0"}},
+			headBranchTags: simver.Tags{simver.Tag{Name: "v1.4.2"}, simver.Tag{Name: "v1.3.0"}},
+			rootBranchTags: mainTags,
+			pr:             0,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.4.3", Ref: head_ref},
+			},
+		},
+		{
+			name:           "pr against a maintenance branch reserves a patch on its line",
+			headBranch:     "fix-thing",
+			baseBranch:     "release/1.4",
+			baseBranchTags: simver.Tags{simver.Tag{Name: "v1.4.2"}},
+			headBranchTags: simver.Tags{simver.Tag{Name: "v1.4.2"}},
+			rootBranchTags: append(mainTags.Copy(), simver.Tag{Name: "v1.4.3-reserved"}),
+			pr:             5,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.4.4-pr5+1", Ref: head_ref},
+				simver.Tag{Name: "v1.4.4-reserved", Ref: root_ref},
+				simver.Tag{Name: "v1.4.4-pr5+base", Ref: base_ref},
+			},
+		},
+		{
+			name:           "merging a pr into a maintenance branch releases the patch",
+			headBranch:     "fix-thing",
+			baseBranch:     "release/1.4",
+			baseBranchTags: simver.Tags{simver.Tag{Name: "v1.4.2"}, simver.Tag{Name: "v1.4.3-pr5+base"}},
+			headBranchTags: simver.Tags{simver.Tag{Name: "v1.4.2"}, simver.Tag{Name: "v1.4.3-pr5+1"}},
+			rootBranchTags: append(mainTags.Copy(), simver.Tag{Name: "v1.4.3-reserved"}),
+			pr:             5,
+			isMerge:        true,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.4.3", Ref: merge_ref},
+			},
+		},
+		{
+			name:           "first push to an untagged line starts at its minor",
+			headBranch:     "release/1.11",
+			baseBranch:     "release/1.11",
+			baseBranchTags: simver.Tags{simver.Tag{Name: "v1.9.0"}},
+			headBranchTags: simver.Tags{simver.Tag{Name: "v1.9.0"}},
+			rootBranchTags: mainTags,
+			pr:             0,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.11.0", Ref: head_ref},
+			},
+		},
+		{
+			name:           "explicit line",
+			headBranch:     "lts",
+			baseBranch:     "lts",
+			baseBranchTags: simver.Tags{simver.Tag{Name: "v1.2.7"}, simver.Tag{Name: "v1.9.0"}},
+			headBranchTags: simver.Tags{simver.Tag{Name: "v1.2.7"}, simver.Tag{Name: "v1.9.0"}},
+			rootBranchTags: mainTags,
+			pr:             0,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.2.8", Ref: head_ref},
+			},
+		},
+		{
+			name:           "hotfix merged forward to root is ignored",
+			headBranch:     "main",
+			baseBranch:     "main",
+			baseBranchTags: simver.Tags{simver.Tag{Name: "v1.8.2"}, simver.Tag{Name: "v1.8.3"}, simver.Tag{Name: "v1.9.0"}, simver.Tag{Name: "v1.9.1"}},
+			headBranchTags: simver.Tags{simver.Tag{Name: "v1.8.3"}, simver.Tag{Name: "v1.9.1"}},
+			rootBranchTags: simver.Tags{simver.Tag{Name: "v1.8.3"}, simver.Tag{Name: "v1.9.1"}},
+			branches:       []string{"main", "release/1.8"},
+			pr:             0,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.10.0", Ref: head_ref},
+			},
+		},
+		{
+			name:           "root keeps lines it has not moved past",
+			headBranch:     "feature",
+			baseBranch:     "main",
+			baseBranchTags: simver.Tags{simver.Tag{Name: "v1.8.0"}, simver.Tag{Name: "v1.9.0"}, simver.Tag{Name: "v1.9.4"}},
+			headBranchTags: simver.Tags{simver.Tag{Name: "v1.9.0"}},
+			rootBranchTags: simver.Tags{simver.Tag{Name: "v1.9.0"}, simver.Tag{Name: "v1.9.5-reserved"}},
+			branches:       []string{"main", "release/1.9", "release/1.10", "feature"},
+			pr:             7,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.10.0-pr7+1", Ref: head_ref},
+				simver.Tag{Name: "v1.10.0-reserved", Ref: root_ref},
+				simver.Tag{Name: "v1.10.0-pr7+base", Ref: base_ref},
+			},
+		},
+		{
+			name:           "maintenance branch for the current root line does not own it",
+			headBranch:     "fix",
+			baseBranch:     "side",
+			baseBranchTags: simver.Tags{simver.Tag{Name: "v1.9.0"}, simver.Tag{Name: "v1.9.4"}},
+			headBranchTags: simver.Tags{simver.Tag{Name: "v1.9.4"}},
+			rootBranchTags: simver.Tags{simver.Tag{Name: "v1.9.0"}, simver.Tag{Name: "v1.9.4"}},
+			branches:       []string{"main", "release/1.9", "side", "fix"},
+			pr:             9,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.9.5-pr9+1", Ref: head_ref},
+				simver.Tag{Name: "v1.9.5-reserved", Ref: root_ref},
+				simver.Tag{Name: "v1.9.5-pr9+base", Ref: base_ref},
+			},
+		},
+		{
+			name:           "back merge from a maintenance branch gets a root version",
+			headBranch:     "release/1.8",
+			baseBranch:     "main",
+			baseBranchTags: simver.Tags{simver.Tag{Name: "v1.8.2"}, simver.Tag{Name: "v1.9.0"}},
+			headBranchTags: simver.Tags{simver.Tag{Name: "v1.8.2"}, simver.Tag{Name: "v1.8.3"}},
+			rootBranchTags: simver.Tags{simver.Tag{Name: "v1.9.0"}, simver.Tag{Name: "v1.8.4-reserved"}},
+			branches:       []string{"main", "release/1.8"},
+			pr:             8,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.10.0-pr8+1", Ref: head_ref},
+				simver.Tag{Name: "v1.10.0-reserved", Ref: root_ref},
+				simver.Tag{Name: "v1.10.0-pr8+base", Ref: base_ref},
+			},
+		},
+		{
+			name:           "pr against root is not affected",
+			headBranch:     "feature",
+			baseBranch:     "main",
+			baseBranchTags: simver.Tags{simver.Tag{Name: "v1.9.0"}, simver.Tag{Name: "v1.4.3"}},
+			headBranchTags: simver.Tags{simver.Tag{Name: "v1.9.0"}},
+			rootBranchTags: mainTags,
+			pr:             6,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.11.0-pr6+1", Ref: head_ref},
+				simver.Tag{Name: "v1.11.0-reserved", Ref: root_ref},
+				simver.Tag{Name: "v1.11.0-pr6+base", Ref: base_ref},
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockExec := new(mockery.MockExecution_simver)
+			mockExec.EXPECT().HeadBranchTags().Return(tc.headBranchTags)
+			mockExec.EXPECT().HeadCommitTags().Return(simver.Tags{})
+			mockExec.EXPECT().BaseBranchTags().Return(tc.baseBranchTags)
+			mockExec.EXPECT().PR().Return(tc.pr)
+			mockExec.EXPECT().IsTargetingRoot().Return(tc.baseBranch == "main")
+			mockExec.EXPECT().IsMerge().Return(tc.isMerge)
+			mockExec.EXPECT().RootBranchTags().Return(tc.rootBranchTags)
+			mockExec.EXPECT().PRTags().Return(simver.Tags{})
+			mockExec.EXPECT().Branches().Return(tc.branches)
+			mockExec.EXPECT().HeadBranch().Return(tc.headBranch)
+			mockExec.EXPECT().BaseBranch().Return(tc.baseBranch)
+
+			calc, err := simver.Calculate(ctx, mockExec, cfg)
+			require.NoError(t, err)
+
+			out, err := calc.CalculateNewTagsRaw(ctx)
+			require.NoError(t, err)
+
+			got := out.ApplyRefs(&simver.BasicRefProvider{
+				HeadRef:  head_ref,
+				BaseRef:  base_ref,
+				RootRef:  root_ref,
+				MergeRef: merge_ref,
+			})
+
+			assert.ElementsMatch(t, tc.expectedTags, got)
+		})
+	}
+}
+
+func TestPushBuilds(t *testing.T) {
+	cfg := &simver.Config{PushBuilds: true}
+
+	testCases := []struct {
+		name           string
+		baseBranchTags simver.Tags
+		pr             int
+		expectedTags   simver.Tags
+	}{
+		{
+			name:           "first push build",
+			baseBranchTags: simver.Tags{simver.Tag{Name: "v1.2.0"}},
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.3.0", Ref: head_ref},
+				simver.Tag{Name: "v1.3.0+build.1", Ref: head_ref},
+			},
+		},
+		{
+			name: "push builds count up across versions",
+			baseBranchTags: simver.Tags{
+				simver.Tag{Name: "v1.2.0"},
+				simver.Tag{Name: "v1.2.0+build.9"},
+				simver.Tag{Name: "v1.3.0"},
+				simver.Tag{Name: "v1.3.0+build.10"},
+			},
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.4.0", Ref: head_ref},
+				simver.Tag{Name: "v1.4.0+build.11", Ref: head_ref},
+			},
+		},
+		{
+			name: "prs do not get push builds",
+			baseBranchTags: simver.Tags{
+				simver.Tag{Name: "v1.2.0"},
+				simver.Tag{Name: "v1.2.0+build.9"},
+			},
+			pr: 3,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.3.0-pr3+1", Ref: head_ref},
+				simver.Tag{Name: "v1.3.0-pr3+base", Ref: base_ref},
+				simver.Tag{Name: "v1.3.0-reserved", Ref: root_ref},
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockExec := new(mockery.MockExecution_simver)
+			mockExec.EXPECT().HeadBranchTags().Return(tc.baseBranchTags)
+			mockExec.EXPECT().HeadCommitTags().Return(simver.Tags{})
+			mockExec.EXPECT().BaseBranchTags().Return(tc.baseBranchTags)
+			mockExec.EXPECT().PR().Return(tc.pr)
+			mockExec.EXPECT().IsTargetingRoot().Return(true)
+			mockExec.EXPECT().IsMerge().Return(false)
+			mockExec.EXPECT().RootBranchTags().Return(tc.baseBranchTags)
+			mockExec.EXPECT().PRTags().Return(simver.Tags{})
+			mockExec.EXPECT().Branches().Return(nil)
+			mockExec.EXPECT().HeadBranch().Return("main")
+			mockExec.EXPECT().BaseBranch().Return("main")
+
+			calc, err := simver.Calculate(ctx, mockExec, cfg)
+			require.NoError(t, err)
+
+			out, err := calc.CalculateNewTagsRaw(ctx)
+			require.NoError(t, err)
+
+			got := out.ApplyRefs(&simver.BasicRefProvider{
+				HeadRef:  head_ref,
+				BaseRef:  base_ref,
+				RootRef:  root_ref,
+				MergeRef: merge_ref,
+			})
+
+			assert.ElementsMatch(t, tc.expectedTags, got)
+
+			tag, _ := out.CurrentBuildTag(&simver.BasicRefProvider{HeadRef: head_ref})
+			assert.Equal(t, tc.expectedTags[0].Name, tag)
+		})
+	}
+}
+
+func TestSkipDirectives(t *testing.T) {
+	cfg := simver.DefaultConfig()
+	cfg.Skip.Paths = []string{"docs/**", "*.md"}
+	cfg.Paths.Exclude = []string{".github/**"}
+	cfg.Paths.Bump = "patch"
+
+	testCases := []struct {
+		name           string
+		pr             int
+		isMerge        bool
+		message        string
+		labels         []string
+		changedFiles   []string
+		headCommitTags simver.Tags
+		expectedReason string
+		expectedTags   simver.Tags
+	}{
+		{
+			name:           "commit message",
+			message:        "fix typo [skip simver]",
+			changedFiles:   []string{"main.go"},
+			expectedReason: `commit message contains "[skip simver]"`,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.2.0+build.4", Ref: head_ref},
+			},
+		},
+		{
+			name:           "label on a merged pr",
+			pr:             5,
+			isMerge:        true,
+			labels:         []string{"bug", "semver:skip"},
+			changedFiles:   []string{"main.go"},
+			expectedReason: `pr has label "semver:skip"`,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.2.0+build.4", Ref: merge_ref},
+			},
+		},
+		{
+			name:           "only skipped paths changed",
+			changedFiles:   []string{"README.md", "docs/guide/intro.md"},
+			expectedReason: "only skip paths changed",
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.2.0+build.4", Ref: head_ref},
+			},
+		},
+		{
+			name:           "already tagged by an earlier run",
+			message:        "[skip simver]",
+			headCommitTags: simver.Tags{simver.Tag{Name: "v1.2.0+build.4"}},
+			expectedReason: `commit message contains "[skip simver]"`,
+			expectedTags:   simver.Tags{},
+		},
+		{
+			name:         "code changed",
+			changedFiles: []string{"README.md", "main.go"},
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.3.0", Ref: head_ref},
+			},
+		},
+		{
+			name:         "excluded paths are a patch",
+			changedFiles: []string{".github/workflows/ci.yaml"},
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.2.1", Ref: head_ref},
+			},
+		},
+		{
+			name:         "skip paths are not excluded from the bump",
+			changedFiles: []string{"README.md", ".github/workflows/ci.yaml"},
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.3.0", Ref: head_ref},
+			},
+		},
+		{
+			name:   "open prs are not skipped",
+			pr:     5,
+			labels: []string{"semver:skip"},
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.3.0-pr5+1", Ref: head_ref},
+				simver.Tag{Name: "v1.3.0-pr5+base", Ref: base_ref},
+				simver.Tag{Name: "v1.3.0-reserved", Ref: root_ref},
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	tags := simver.Tags{
+		simver.Tag{Name: "v1.2.0"},
+		simver.Tag{Name: "v1.2.0+build.3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockExec := new(mockery.MockExecution_simver)
+			mockExec.EXPECT().HeadBranchTags().Return(tags)
+			mockExec.EXPECT().HeadCommitTags().Return(tc.headCommitTags)
+			mockExec.EXPECT().BaseBranchTags().Return(tags)
+			mockExec.EXPECT().PR().Return(tc.pr)
+			mockExec.EXPECT().IsTargetingRoot().Return(true)
+			mockExec.EXPECT().IsMerge().Return(tc.isMerge)
+			mockExec.EXPECT().RootBranchTags().Return(tags)
+			mockExec.EXPECT().PRTags().Return(simver.Tags{})
+			mockExec.EXPECT().Branches().Return(nil)
+			mockExec.EXPECT().HeadBranch().Return("feature")
+			mockExec.EXPECT().BaseBranch().Return("main")
+			mockExec.EXPECT().HeadCommitMessage().Return(tc.message)
+			mockExec.EXPECT().Labels().Return(tc.labels)
+			mockExec.EXPECT().ChangedFiles().Return(tc.changedFiles)
+
+			calc, err := simver.Calculate(ctx, mockExec, cfg)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedReason, calc.SkipReason)
+
+			out, err := calc.CalculateNewTagsRaw(ctx)
+			require.NoError(t, err)
+
+			got := out.ApplyRefs(&simver.BasicRefProvider{
+				HeadRef:  head_ref,
+				BaseRef:  base_ref,
+				RootRef:  root_ref,
+				MergeRef: merge_ref,
+			})
+
+			assert.ElementsMatch(t, tc.expectedTags, got)
+		})
+	}
+}
+
+func TestPathFilters(t *testing.T) {
+	exclude := simver.PathsConfig{Exclude: []string{"docs/**", ".github/**", "*.md"}}
+
+	testCases := []struct {
+		name           string
+		paths          simver.PathsConfig
+		pr             int
+		changedFiles   []string
+		expectedReason string
+		expectedTags   simver.Tags
+	}{
+		{
+			name:         "docs only push is downgraded to a patch",
+			paths:        exclude,
+			changedFiles: []string{"README.md", "docs/guide.md", ".github/workflows/ci.yaml"},
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.2.1", Ref: head_ref},
+			},
+		},
+		{
+			name:         "code changes bump as usual",
+			paths:        exclude,
+			changedFiles: []string{"README.md", "execution.go"},
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.3.0", Ref: head_ref},
+			},
+		},
+		{
+			name:         "unknown changes bump as usual",
+			paths:        exclude,
+			changedFiles: []string{},
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.3.0", Ref: head_ref},
+			},
+		},
+		{
+			name:         "docs only pr reserves a patch",
+			paths:        exclude,
+			pr:           5,
+			changedFiles: []string{"docs/guide.md"},
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.2.1-pr5+1", Ref: head_ref},
+				simver.Tag{Name: "v1.2.1-pr5+base", Ref: base_ref},
+				simver.Tag{Name: "v1.2.1-reserved", Ref: root_ref},
+			},
+		},
+		{
+			name:         "only included paths count",
+			paths:        simver.PathsConfig{Include: []string{"src/**"}},
+			changedFiles: []string{"tools/gen.go"},
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.2.1", Ref: head_ref},
+			},
+		},
+		{
+			name:           "docs only push is skipped",
+			paths:          simver.PathsConfig{Exclude: exclude.Exclude, Bump: "skip"},
+			changedFiles:   []string{"docs/guide.md"},
+			expectedReason: "no changed files count towards a bump",
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.2.0+build.1", Ref: head_ref},
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	tags := simver.Tags{simver.Tag{Name: "v1.2.0"}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockExec := new(mockery.MockExecution_simver)
+			mockExec.EXPECT().HeadBranchTags().Return(tags)
+			mockExec.EXPECT().HeadCommitTags().Return(simver.Tags{})
+			mockExec.EXPECT().BaseBranchTags().Return(tags)
+			mockExec.EXPECT().PR().Return(tc.pr)
+			mockExec.EXPECT().IsTargetingRoot().Return(true)
+			mockExec.EXPECT().IsMerge().Return(false)
+			mockExec.EXPECT().RootBranchTags().Return(tags)
+			mockExec.EXPECT().PRTags().Return(simver.Tags{})
+			mockExec.EXPECT().Branches().Return(nil)
+			mockExec.EXPECT().HeadBranch().Return("feature")
+			mockExec.EXPECT().BaseBranch().Return("main")
+			mockExec.EXPECT().ChangedFiles().Return(tc.changedFiles)
+
+			calc, err := simver.Calculate(ctx, mockExec, &simver.Config{Paths: tc.paths})
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedReason, calc.SkipReason)
+
+			out, err := calc.CalculateNewTagsRaw(ctx)
+			require.NoError(t, err)
+
+			got := out.ApplyRefs(&simver.BasicRefProvider{
+				HeadRef:  head_ref,
+				BaseRef:  base_ref,
+				RootRef:  root_ref,
+				MergeRef: merge_ref,
+			})
+
+			assert.ElementsMatch(t, tc.expectedTags, got)
+		})
+	}
+}
+
+func TestLabelBumps(t *testing.T) {
+	testCases := []struct {
+		name            string
+		labels          []string
+		headBranchTags  simver.Tags
+		rootBranchTags  simver.Tags
+		isMerge         bool
+		isTargetingRoot bool
+		expectedTags    simver.Tags
+	}{
+		{
+			name:            "patch label on a pr to root",
+			labels:          []string{"semver:patch"},
+			isTargetingRoot: true,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.2.1-pr5+1", Ref: head_ref},
+				simver.Tag{Name: "v1.2.1-pr5+base", Ref: base_ref},
+				simver.Tag{Name: "v1.2.1-reserved", Ref: root_ref},
+			},
+		},
+		{
+			name:            "major label on a pr to root",
+			labels:          []string{"semver:major"},
+			isTargetingRoot: true,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v2.0.0-pr5+1", Ref: head_ref},
+				simver.Tag{Name: "v2.0.0-pr5+base", Ref: base_ref},
+				simver.Tag{Name: "v2.0.0-reserved", Ref: root_ref},
+			},
+		},
+		{
+			name:            "biggest label wins",
+			labels:          []string{"semver:patch", "docs", "semver:major", "semver:minor"},
+			isTargetingRoot: true,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v2.0.0-pr5+1", Ref: head_ref},
+				simver.Tag{Name: "v2.0.0-pr5+base", Ref: base_ref},
+				simver.Tag{Name: "v2.0.0-reserved", Ref: root_ref},
+			},
+		},
+		{
+			name:            "minor label on a pr to a side branch",
+			labels:          []string{"semver:minor"},
+			isTargetingRoot: false,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.3.0-pr5+1", Ref: head_ref},
+				simver.Tag{Name: "v1.3.0-pr5+base", Ref: base_ref},
+				simver.Tag{Name: "v1.3.0-reserved", Ref: root_ref},
+			},
+		},
+		{
+			name:            "patch label added after a minor was reserved",
+			labels:          []string{"semver:patch"},
+			headBranchTags:  simver.Tags{simver.Tag{Name: "v1.3.0-pr5+1"}},
+			rootBranchTags:  simver.Tags{simver.Tag{Name: "v1.3.0-reserved"}},
+			isTargetingRoot: true,
+			// a smaller change keeps the version it already reserved
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.3.0-pr5+2", Ref: head_ref},
+			},
+		},
+		{
+			name:            "patch label after another pr reserved the minor",
+			labels:          []string{"semver:patch"},
+			headBranchTags:  simver.Tags{simver.Tag{Name: "v1.3.1-pr5+1"}},
+			rootBranchTags:  simver.Tags{simver.Tag{Name: "v1.3.0-reserved"}, simver.Tag{Name: "v1.3.1-reserved"}},
+			isTargetingRoot: true,
+			// v1.3.1 was reserved as a patch after v1.3.0, so it is kept
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.3.1-pr5+2", Ref: head_ref},
+			},
+		},
+		{
+			name:            "label matching the reserved version",
+			labels:          []string{"semver:patch"},
+			headBranchTags:  simver.Tags{simver.Tag{Name: "v1.2.1-pr5+1"}},
+			rootBranchTags:  simver.Tags{simver.Tag{Name: "v1.2.1-reserved"}},
+			isTargetingRoot: true,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.2.1-pr5+2", Ref: head_ref},
+			},
+		},
+		{
+			name:            "merge with a patch label",
+			labels:          []string{"semver:patch"},
+			headBranchTags:  simver.Tags{simver.Tag{Name: "v1.2.1-pr5+2"}},
+			rootBranchTags:  simver.Tags{simver.Tag{Name: "v1.2.1-reserved"}},
+			isMerge:         true,
+			isTargetingRoot: true,
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.2.1", Ref: merge_ref},
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			base := simver.Tags{simver.Tag{Name: "v1.2.0"}}
+
+			mockExec := new(mockery.MockExecution_simver)
+			mockExec.EXPECT().HeadBranchTags().Return(append(base.Copy(), tc.headBranchTags...))
+			mockExec.EXPECT().HeadCommitTags().Return(simver.Tags{})
+			mockExec.EXPECT().BaseBranchTags().Return(base)
+			mockExec.EXPECT().PR().Return(5)
+			mockExec.EXPECT().IsTargetingRoot().Return(tc.isTargetingRoot)
+			mockExec.EXPECT().IsMerge().Return(tc.isMerge)
+			mockExec.EXPECT().RootBranchTags().Return(append(base.Copy(), tc.rootBranchTags...))
+			mockExec.EXPECT().PRTags().Return(simver.Tags{})
+			mockExec.EXPECT().Branches().Return(nil)
+			mockExec.EXPECT().HeadBranch().Return("feature")
+			mockExec.EXPECT().BaseBranch().Return("main")
+			mockExec.EXPECT().HeadCommitMessage().Return("")
+			mockExec.EXPECT().Labels().Return(tc.labels)
+
+			calc, err := simver.Calculate(ctx, mockExec, simver.DefaultConfig())
+			require.NoError(t, err)
+
+			out, err := calc.CalculateNewTagsRaw(ctx)
+			require.NoError(t, err)
+
+			got := out.ApplyRefs(&simver.BasicRefProvider{
+				HeadRef:  head_ref,
+				BaseRef:  base_ref,
+				RootRef:  root_ref,
+				MergeRef: merge_ref,
+			})
+
+			assert.ElementsMatch(t, tc.expectedTags, got)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package simver
+
+import (
+	"fmt"
+)
+
+// LiveReleases returns the release tags in out that are new versions on the root branch. Releases pushed to
+// maintenance lines and channels never are.
+func LiveReleases(ex Execution, out *CalculationOutput, refs RefProvider, cfg *Config) Tags {
+	if !ReleasesOnRoot(ex, cfg) {
+		return Tags{}
+	}
+
+	tags := Tags{}
+
+	for _, t := range out.ApplyRefs(refs) {
+		if v, err := ParseVersion(t.Name); err == nil && v.Role == TagRoleRelease {
+			tags = append(tags, t)
+		}
+	}
+
+	return tags
+}
+
+// FloatingTags returns the vN and vN.M tags to move to the given live releases. An alias is never moved
+// backwards: it is skipped if existing already has a higher release in its major or minor line.
+func FloatingTags(live Tags, existing Tags) Tags {
+	releases := existing.Versions().WithRole(TagRoleRelease)
+
+	tags := Tags{}
+
+	for _, t := range live {
+		v, err := ParseVersion(t.Name)
+		if err != nil || v.Role != TagRoleRelease {
+			continue
+		}
+
+		line := releases.WithPrefix(v.Prefix)
+
+		if top, ok := line.Filter(func(o Version) bool { return o.Major == v.Major }).Highest(); !ok || top.Compare(v) <= 0 {
+			tags = append(tags, Tag{Name: fmt.Sprintf("%sv%d", v.Prefix, v.Major), Ref: t.Ref})
+		}
+
+		if top, ok := line.Filter(func(o Version) bool { return o.Major == v.Major && o.Minor == v.Minor }).Highest(); !ok || top.Compare(v) <= 0 {
+			tags = append(tags, Tag{Name: fmt.Sprintf("%sv%d.%d", v.Prefix, v.Major, v.Minor), Ref: t.Ref})
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,95 @@
+package simver_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walteh/simver"
+	"github.com/walteh/simver/gen/mockery"
+)
+
+func TestLiveReleases(t *testing.T) {
+	refs := &simver.BasicRefProvider{HeadRef: "head_ref", MergeRef: "merge_ref", BaseRef: "base_ref", RootRef: "root_ref"}
+
+	out := &simver.CalculationOutput{
+		HeadTags:  []string{"v1.3.0", "v1.3.0+build.4"},
+		MergeTags: []string{"v1.4.0"},
+		RootTags:  []string{"v1.5.0-reserved"},
+	}
+
+	cfg := simver.DefaultConfig()
+	cfg.Lines = []*simver.LineConfig{{Branch: "release/*"}}
+
+	mockExec := new(mockery.MockExecution_simver)
+	mockExec.EXPECT().IsTargetingRoot().Return(true)
+	mockExec.EXPECT().BaseBranch().Return("main")
+
+	assert.ElementsMatch(t, simver.Tags{
+		simver.Tag{Name: "v1.3.0", Ref: "head_ref"},
+		simver.Tag{Name: "v1.4.0", Ref: "merge_ref"},
+	}, simver.LiveReleases(mockExec, out, refs, cfg))
+
+	sideExec := new(mockery.MockExecution_simver)
+	sideExec.EXPECT().IsTargetingRoot().Return(false)
+
+	assert.Empty(t, simver.LiveReleases(sideExec, out, refs, cfg))
+
+	// a push to a maintenance line is simulated as a pr into the line itself
+	lineExec := new(mockery.MockExecution_simver)
+	lineExec.EXPECT().IsTargetingRoot().Return(true)
+	lineExec.EXPECT().BaseBranch().Return("release/1.3")
+
+	assert.Empty(t, simver.LiveReleases(lineExec, out, refs, cfg))
+}
+
+func TestFloatingTags(t *testing.T) {
+	testCases := []struct {
+		name     string
+		live     simver.Tags
+		existing simver.Tags
+		expected simver.Tags
+	}{
+		{
+			name:     "first release",
+			live:     simver.Tags{{Name: "v0.1.0", Ref: "abc"}},
+			existing: simver.Tags{{Name: "v0.1.0"}},
+			expected: simver.Tags{{Name: "v0", Ref: "abc"}, {Name: "v0.1", Ref: "abc"}},
+		},
+		{
+			name:     "new minor",
+			live:     simver.Tags{{Name: "v1.3.0", Ref: "abc"}},
+			existing: simver.Tags{{Name: "v1.2.0"}, {Name: "v1.2.1"}, {Name: "v1"}, {Name: "v1.2"}, {Name: "v1.3.0"}},
+			expected: simver.Tags{{Name: "v1", Ref: "abc"}, {Name: "v1.3", Ref: "abc"}},
+		},
+		{
+			name:     "older line does not move the major",
+			live:     simver.Tags{{Name: "v1.2.2", Ref: "abc"}},
+			existing: simver.Tags{{Name: "v1.2.1"}, {Name: "v1.3.0"}, {Name: "v1.2.2"}},
+			expected: simver.Tags{{Name: "v1.2", Ref: "abc"}},
+		},
+		{
+			name:     "never backwards",
+			live:     simver.Tags{{Name: "v1.2.2", Ref: "abc"}},
+			existing: simver.Tags{{Name: "v1.2.3"}, {Name: "v1.3.0"}},
+			expected: simver.Tags{},
+		},
+		{
+			name:     "prereleases are ignored",
+			live:     simver.Tags{{Name: "v2.0.0-rc.1", Ref: "abc"}},
+			existing: simver.Tags{{Name: "v1.3.0"}},
+			expected: simver.Tags{},
+		},
+		{
+			name:     "module prefix",
+			live:     simver.Tags{{Name: "tools/v0.4.0", Ref: "abc"}},
+			existing: simver.Tags{{Name: "v0.9.0"}, {Name: "tools/v0.3.0"}},
+			expected: simver.Tags{{Name: "tools/v0", Ref: "abc"}, {Name: "tools/v0.4", Ref: "abc"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, simver.FloatingTags(tc.live, tc.existing))
+		})
+	}
+}
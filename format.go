@@ -0,0 +1,284 @@
+package simver
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gitlab.com/tozd/go/errors"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	DefaultPRTemplate       = "-pr{pr}+{build}"
+	DefaultBaseTemplate     = "-pr{pr}+base"
+	DefaultReservedTemplate = "-reserved"
+	DefaultBuildTemplate    = "+build.{build}"
+)
+
+// TagFormat is the single source of truth for the shape of simver's own tags. Each template is the suffix
+// appended to a version and can use the {pr} and {build} placeholders, e.g. "-pr.{pr}.{build}".
+type TagFormat struct {
+	pr       string
+	base     string
+	reserved string
+	build    string
+
+	prRegex       *regexp.Regexp
+	baseRegex     *regexp.Regexp
+	reservedRegex *regexp.Regexp
+	buildRegex    *regexp.Regexp
+
+	fallback *TagFormat // the default format, for tags from before the templates were changed
+}
+
+var DefaultTagFormat = MustNewTagFormat(DefaultPRTemplate, DefaultBaseTemplate, DefaultReservedTemplate, DefaultBuildTemplate)
+
+var releaseTagRegex = regexp.MustCompile(`^v\d+\.\d+\.\d+$`)
+
+// channel prereleases are not configurable, they are named after the channel, e.g. v2.0.0-rc.3
+var channelTagRegex = regexp.MustCompile(`^v\d+\.\d+\.\d+-(?P<channel>[0-9A-Za-z-]*[A-Za-z-][0-9A-Za-z-]*)\.(?P<build>\d+)$`)
+
+// NewTagFormat validates the templates, empty templates fall back to the defaults
+func NewTagFormat(pr, base, reserved, build string) (*TagFormat, error) {
+	if pr == "" {
+		pr = DefaultPRTemplate
+	}
+	if base == "" {
+		base = DefaultBaseTemplate
+	}
+	if reserved == "" {
+		reserved = DefaultReservedTemplate
+	}
+	if build == "" {
+		build = DefaultBuildTemplate
+	}
+
+	f := &TagFormat{pr: pr, base: base, reserved: reserved, build: build}
+
+	for _, t := range []struct {
+		name     string
+		template string
+		start    string
+		needs    []string
+		forbids  []string
+		dst      **regexp.Regexp
+	}{
+		{name: "pr", template: pr, start: "-", needs: []string{"{pr}", "{build}"}, dst: &f.prRegex},
+		{name: "base", template: base, start: "-", needs: []string{"{pr}"}, forbids: []string{"{build}"}, dst: &f.baseRegex},
+		{name: "reserved", template: reserved, start: "-", forbids: []string{"{pr}", "{build}"}, dst: &f.reservedRegex},
+		// push builds are the release itself, so they can only add build metadata
+		{name: "build", template: build, start: "+", needs: []string{"{build}"}, forbids: []string{"{pr}"}, dst: &f.buildRegex},
+	} {
+		// prerelease tags sort below the release, build metadata tags are equal to it
+		if !strings.HasPrefix(t.template, t.start) {
+			return nil, errors.Errorf("invalid %s tag template %q: must start with %s", t.name, t.template, t.start)
+		}
+
+		for _, n := range t.needs {
+			if strings.Count(t.template, n) != 1 {
+				return nil, errors.Errorf("invalid %s tag template %q: must contain %s exactly once", t.name, t.template, n)
+			}
+		}
+
+		for _, n := range t.forbids {
+			if strings.Contains(t.template, n) {
+				return nil, errors.Errorf("invalid %s tag template %q: cannot contain %s", t.name, t.template, n)
+			}
+		}
+
+		*t.dst = templateRegex(t.template)
+	}
+
+	// every template has to render to valid semver, and be read back as what it is. Numbers of different
+	// lengths catch templates where they can't be told apart, like -pr{pr}{build} (pr 12 build 3 and pr 1 build 23).
+	for _, n := range []struct{ pr, build int }{{4, 5}, {12, 3}, {1, 23}} {
+		for _, t := range []struct {
+			role  TagRole
+			name  string
+			pr    int
+			build int
+		}{
+			{TagRolePR, f.PRTag("v1.2.3", n.pr, n.build), n.pr, n.build},
+			{TagRoleBase, f.BaseTag("v1.2.3", n.pr), n.pr, 0},
+			{TagRoleReserved, f.ReservedTag("v1.2.3"), 0, 0},
+			{TagRoleBuild, f.BuildTag("v1.2.3", n.build), 0, n.build},
+		} {
+			if !versionRegex.MatchString(t.name) || !semver.IsValid(t.name) {
+				return nil, errors.Errorf("invalid %s tag template: %q is not a valid version", t.role, t.name)
+			}
+
+			if role, pr, build := f.parse(t.name); role != t.role || pr != t.pr || build != t.build {
+				return nil, errors.Errorf("invalid %s tag template: %q is ambiguous", t.role, t.name)
+			}
+		}
+	}
+
+	if !f.IsDefault() {
+		fallback, err := NewTagFormat("", "", "", "")
+		if err != nil {
+			return nil, err
+		}
+		f.fallback = fallback
+	}
+
+	return f, nil
+}
+
+func MustNewTagFormat(pr, base, reserved, build string) *TagFormat {
+	f, err := NewTagFormat(pr, base, reserved, build)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// templateRegex turns a template into a regex matching a version with that suffix
+func templateRegex(template string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(template)
+	expr = strings.Replace(expr, regexp.QuoteMeta("{pr}"), `(?P<pr>\d+)`, 1)
+	expr = strings.Replace(expr, regexp.QuoteMeta("{build}"), `(?P<build>\d+)`, 1)
+	return regexp.MustCompile(`^v\d+\.\d+\.\d+` + expr + `$`)
+}
+
+func render(template string, pr, build int) string {
+	out := strings.ReplaceAll(template, "{pr}", strconv.Itoa(pr))
+	return strings.ReplaceAll(out, "{build}", strconv.Itoa(build))
+}
+
+func (f *TagFormat) PRTag(version string, pr, build int) string {
+	return version + render(f.pr, pr, build)
+}
+
+func (f *TagFormat) BaseTag(version string, pr int) string {
+	return version + render(f.base, pr, 0)
+}
+
+func (f *TagFormat) ReservedTag(version string) string {
+	return version + f.reserved
+}
+
+// BuildTag names the build of a push, e.g. v1.2.3+build.5
+func (f *TagFormat) BuildTag(version string, build int) string {
+	return version + render(f.build, 0, build)
+}
+
+// ChannelTag names a prerelease on a channel, e.g. v2.0.0-rc.3
+func (f *TagFormat) ChannelTag(version, channel string, build int) string {
+	return version + "-" + channel + "." + strconv.Itoa(build)
+}
+
+// PRPattern is a glob matching every build tag of a pr, for git tag --list
+func (f *TagFormat) PRPattern(pr int) string {
+	return "*" + strings.ReplaceAll(strings.ReplaceAll(f.pr, "{pr}", strconv.Itoa(pr)), "{build}", "*")
+}
+
+// PRPatterns are the globs matching every build tag of a pr, in this format and in the default format,
+// since builds from before the templates were changed still count
+func (f *TagFormat) PRPatterns(pr int) []string {
+	if f.fallback == nil {
+		return []string{f.PRPattern(pr)}
+	}
+	return []string{f.PRPattern(pr), f.fallback.PRPattern(pr)}
+}
+
+// RoleOf classifies a tag name (without any module prefix) by what simver uses it for
+func (f *TagFormat) RoleOf(name string) TagRole {
+	role, _, _ := f.parse(name)
+	return role
+}
+
+// parse returns the role of name, along with the pr number for pr and base tags and the build number for pr, build and channel tags
+func (f *TagFormat) parse(name string) (TagRole, int, int) {
+	if !versionRegex.MatchString(name) || !semver.IsValid(name) {
+		return TagRoleInvalid, 0, 0
+	}
+
+	if releaseTagRegex.MatchString(name) {
+		return TagRoleRelease, 0, 0
+	}
+
+	if role, pr, build, ok := f.match(name); ok {
+		return role, pr, build
+	}
+
+	// tags from before the templates were changed are still understood
+	if f.fallback != nil {
+		if role, pr, build, ok := f.fallback.match(name); ok {
+			return role, pr, build
+		}
+	}
+
+	if m := channelTagRegex.FindStringSubmatch(name); m != nil {
+		build, _ := strconv.Atoi(m[channelTagRegex.SubexpIndex("build")])
+		return TagRoleChannel, 0, build
+	}
+
+	return TagRoleOther, 0, 0
+}
+
+// match finds the template name was rendered from
+func (f *TagFormat) match(name string) (TagRole, int, int, bool) {
+	for _, t := range []struct {
+		role TagRole
+		reg  *regexp.Regexp
+	}{
+		{TagRolePR, f.prRegex},
+		{TagRoleBase, f.baseRegex},
+		{TagRoleReserved, f.reservedRegex},
+		{TagRoleBuild, f.buildRegex},
+	} {
+		m := t.reg.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+
+		pr, build := 0, 0
+		if i := t.reg.SubexpIndex("pr"); i > 0 {
+			pr, _ = strconv.Atoi(m[i])
+		}
+		if i := t.reg.SubexpIndex("build"); i > 0 {
+			build, _ = strconv.Atoi(m[i])
+		}
+
+		return t.role, pr, build, true
+	}
+
+	return "", 0, 0, false
+}
+
+// Canonical renames a tag (without any module prefix) of this format to the default format,
+// which is what the calculation works with. Any other tag is returned as is.
+func (f *TagFormat) Canonical(name string) string {
+	role, pr, build := f.parse(name)
+
+	if role != TagRolePR && role != TagRoleBase && role != TagRoleReserved && role != TagRoleBuild {
+		return name
+	}
+
+	core := name[:strings.IndexAny(name, "-+")]
+
+	switch role {
+	case TagRolePR:
+		return DefaultTagFormat.PRTag(core, pr, build)
+	case TagRoleBase:
+		return DefaultTagFormat.BaseTag(core, pr)
+	case TagRoleReserved:
+		return DefaultTagFormat.ReservedTag(core)
+	case TagRoleBuild:
+		return DefaultTagFormat.BuildTag(core, build)
+	default:
+		return name
+	}
+}
+
+func (f *TagFormat) IsDefault() bool {
+	return f == nil || (f.pr == DefaultPRTemplate && f.base == DefaultBaseTemplate && f.reserved == DefaultReservedTemplate && f.build == DefaultBuildTemplate)
+}
+
+func tagFormatOrDefault(f *TagFormat) *TagFormat {
+	if f == nil {
+		return DefaultTagFormat
+	}
+	return f
+}
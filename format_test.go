@@ -0,0 +1,155 @@
+package simver_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+	"github.com/walteh/simver/gen/mockery"
+)
+
+func TestNewTagFormat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pr       string
+		base     string
+		reserved string
+		build    string
+		err      bool
+	}{
+		{name: "defaults"},
+		{name: "dotted", pr: "-pr.{pr}.{build}", base: "-pr.{pr}.base", reserved: "-rsv"},
+		{name: "missing build", pr: "-pr.{pr}", err: true},
+		{name: "missing pr", base: "-base", err: true},
+		{name: "build in base", base: "-pr{pr}+{build}.base", err: true},
+		{name: "placeholder in reserved", reserved: "-reserved.{pr}", err: true},
+		{name: "not a prerelease", pr: "+pr{pr}.{build}", err: true},
+		{name: "invalid semver", pr: "-pr_{pr}+{build}", err: true},
+		{name: "custom build", build: "+b{build}"},
+		{name: "build as prerelease", build: "-build.{build}", err: true},
+		{name: "missing build number", build: "+build", err: true},
+		{name: "ambiguous", pr: "-x{pr}+{build}", base: "-x{pr}+0", err: true},
+		{name: "adjacent placeholders", pr: "-pr{pr}{build}", err: true},
+		{name: "separated placeholders", pr: "-pr{pr}x{build}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := simver.NewTagFormat(tc.pr, tc.base, tc.reserved, tc.build)
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTagFormatRoundTrip(t *testing.T) {
+	f := simver.MustNewTagFormat("-pr.{pr}.{build}", "-pr.{pr}.base", "-rsv", "+b.{build}")
+
+	assert.Equal(t, "v1.2.3-pr.4.5", f.PRTag("v1.2.3", 4, 5))
+	assert.Equal(t, "v1.2.3-pr.4.base", f.BaseTag("v1.2.3", 4))
+	assert.Equal(t, "v1.2.3-rsv", f.ReservedTag("v1.2.3"))
+	assert.Equal(t, "v1.2.3+b.6", f.BuildTag("v1.2.3", 6))
+	assert.Equal(t, "v1.2.3-rc.7", f.ChannelTag("v1.2.3", "rc", 7))
+	assert.Equal(t, "*-pr.4.*", f.PRPattern(4))
+
+	assert.Equal(t, simver.TagRolePR, f.RoleOf("v1.2.3-pr.4.5"))
+	assert.Equal(t, simver.TagRoleBase, f.RoleOf("v1.2.3-pr.4.base"))
+	assert.Equal(t, simver.TagRoleReserved, f.RoleOf("v1.2.3-rsv"))
+	assert.Equal(t, simver.TagRoleBuild, f.RoleOf("v1.2.3+b.6"))
+	assert.Equal(t, simver.TagRoleChannel, f.RoleOf("v1.2.3-rc.7"))
+	assert.Equal(t, simver.TagRoleRelease, f.RoleOf("v1.2.3"))
+	// tags from before the templates were changed
+	assert.Equal(t, simver.TagRolePR, f.RoleOf("v1.2.3-pr4+5"))
+	assert.Equal(t, simver.TagRoleBase, f.RoleOf("v1.2.3-pr4+base"))
+	assert.Equal(t, simver.TagRoleReserved, f.RoleOf("v1.2.3-reserved"))
+	assert.Equal(t, "v1.2.3-pr4+5", f.Canonical("v1.2.3-pr4+5"))
+
+	assert.Equal(t, "v1.2.3-pr4+5", f.Canonical("v1.2.3-pr.4.5"))
+	assert.Equal(t, "v1.2.3-pr4+base", f.Canonical("v1.2.3-pr.4.base"))
+	assert.Equal(t, "v1.2.3-reserved", f.Canonical("v1.2.3-rsv"))
+	assert.Equal(t, "v1.2.3+build.6", f.Canonical("v1.2.3+b.6"))
+	assert.Equal(t, "v1.2.3-rc.1", f.Canonical("v1.2.3-rc.1"))
+
+	assert.Equal(t, "*-pr4+*", simver.DefaultTagFormat.PRPattern(4))
+	assert.Equal(t, []string{"*-pr.4.*", "*-pr4+*"}, f.PRPatterns(4))
+	assert.Equal(t, []string{"*-pr4+*"}, simver.DefaultTagFormat.PRPatterns(4))
+}
+
+func TestCustomTagFormatCalculation(t *testing.T) {
+	cfg := &simver.Config{
+		Tags: simver.TagsConfig{PR: "-pr.{pr}.{build}", Base: "-pr.{pr}.base", Reserved: "-rsv"},
+	}
+
+	testCases := []struct {
+		name           string
+		headBranchTags simver.Tags
+		rootBranchTags simver.Tags
+		expectedTags   simver.Tags
+	}{
+		{
+			name:           "first build",
+			headBranchTags: simver.Tags{simver.Tag{Name: "v1.2.0"}},
+			rootBranchTags: simver.Tags{simver.Tag{Name: "v1.2.0"}, simver.Tag{Name: "v1.3.0-rsv"}},
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.4.0-pr.4.1", Ref: head_ref},
+				simver.Tag{Name: "v1.4.0-pr.4.base", Ref: base_ref},
+				simver.Tag{Name: "v1.4.0-rsv", Ref: root_ref},
+			},
+		},
+		{
+			name:           "next build",
+			headBranchTags: simver.Tags{simver.Tag{Name: "v1.2.0"}, simver.Tag{Name: "v1.3.0-pr.4.1"}},
+			rootBranchTags: simver.Tags{simver.Tag{Name: "v1.2.0"}, simver.Tag{Name: "v1.3.0-rsv"}},
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.3.0-pr.4.2", Ref: head_ref},
+			},
+		},
+		{
+			name:           "builds from before the templates were changed",
+			headBranchTags: simver.Tags{simver.Tag{Name: "v1.2.0"}, simver.Tag{Name: "v1.3.0-pr4+2"}},
+			rootBranchTags: simver.Tags{simver.Tag{Name: "v1.2.0"}, simver.Tag{Name: "v1.3.0-reserved"}},
+			expectedTags: simver.Tags{
+				simver.Tag{Name: "v1.3.0-pr.4.3", Ref: head_ref},
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockExec := new(mockery.MockExecution_simver)
+			mockExec.EXPECT().HeadBranchTags().Return(tc.headBranchTags)
+			mockExec.EXPECT().HeadCommitTags().Return(simver.Tags{})
+			mockExec.EXPECT().BaseBranchTags().Return(simver.Tags{simver.Tag{Name: "v1.2.0"}})
+			mockExec.EXPECT().PR().Return(4)
+			mockExec.EXPECT().IsTargetingRoot().Return(true)
+			mockExec.EXPECT().IsMerge().Return(false)
+			mockExec.EXPECT().RootBranchTags().Return(tc.rootBranchTags)
+			mockExec.EXPECT().PRTags().Return(tc.headBranchTags)
+			mockExec.EXPECT().Branches().Return(nil)
+			mockExec.EXPECT().HeadBranch().Return("feature")
+			mockExec.EXPECT().BaseBranch().Return("main")
+
+			calc, err := simver.Calculate(ctx, mockExec, cfg)
+			require.NoError(t, err)
+
+			out, err := calc.CalculateNewTagsRaw(ctx)
+			require.NoError(t, err)
+
+			got := out.ApplyRefs(&simver.BasicRefProvider{
+				HeadRef:  head_ref,
+				BaseRef:  base_ref,
+				RootRef:  root_ref,
+				MergeRef: merge_ref,
+			})
+
+			assert.ElementsMatch(t, tc.expectedTags, got)
+		})
+	}
+}
@@ -20,6 +20,51 @@ func (_m *MockExecution_simver) EXPECT() *MockExecution_simver_Expecter {
 	return &MockExecution_simver_Expecter{mock: &_m.Mock}
 }
 
+// BaseBranch provides a mock function with given fields:
+func (_m *MockExecution_simver) BaseBranch() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for BaseBranch")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// MockExecution_simver_BaseBranch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BaseBranch'
+type MockExecution_simver_BaseBranch_Call struct {
+	*mock.Call
+}
+
+// BaseBranch is a helper method to define mock.On call
+func (_e *MockExecution_simver_Expecter) BaseBranch() *MockExecution_simver_BaseBranch_Call {
+	return &MockExecution_simver_BaseBranch_Call{Call: _e.mock.On("BaseBranch")}
+}
+
+func (_c *MockExecution_simver_BaseBranch_Call) Run(run func()) *MockExecution_simver_BaseBranch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecution_simver_BaseBranch_Call) Return(_a0 string) *MockExecution_simver_BaseBranch_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecution_simver_BaseBranch_Call) RunAndReturn(run func() string) *MockExecution_simver_BaseBranch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // BaseBranchTags provides a mock function with given fields:
 func (_m *MockExecution_simver) BaseBranchTags() simver.Tags {
 	ret := _m.Called()
@@ -67,6 +112,145 @@ func (_c *MockExecution_simver_BaseBranchTags_Call) RunAndReturn(run func() simv
 	return _c
 }
 
+// Branches provides a mock function with given fields:
+func (_m *MockExecution_simver) Branches() []string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Branches")
+	}
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// MockExecution_simver_Branches_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Branches'
+type MockExecution_simver_Branches_Call struct {
+	*mock.Call
+}
+
+// Branches is a helper method to define mock.On call
+func (_e *MockExecution_simver_Expecter) Branches() *MockExecution_simver_Branches_Call {
+	return &MockExecution_simver_Branches_Call{Call: _e.mock.On("Branches")}
+}
+
+func (_c *MockExecution_simver_Branches_Call) Run(run func()) *MockExecution_simver_Branches_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecution_simver_Branches_Call) Return(_a0 []string) *MockExecution_simver_Branches_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecution_simver_Branches_Call) RunAndReturn(run func() []string) *MockExecution_simver_Branches_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ChangedFiles provides a mock function with given fields:
+func (_m *MockExecution_simver) ChangedFiles() []string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangedFiles")
+	}
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// MockExecution_simver_ChangedFiles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ChangedFiles'
+type MockExecution_simver_ChangedFiles_Call struct {
+	*mock.Call
+}
+
+// ChangedFiles is a helper method to define mock.On call
+func (_e *MockExecution_simver_Expecter) ChangedFiles() *MockExecution_simver_ChangedFiles_Call {
+	return &MockExecution_simver_ChangedFiles_Call{Call: _e.mock.On("ChangedFiles")}
+}
+
+func (_c *MockExecution_simver_ChangedFiles_Call) Run(run func()) *MockExecution_simver_ChangedFiles_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecution_simver_ChangedFiles_Call) Return(_a0 []string) *MockExecution_simver_ChangedFiles_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecution_simver_ChangedFiles_Call) RunAndReturn(run func() []string) *MockExecution_simver_ChangedFiles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HeadBranch provides a mock function with given fields:
+func (_m *MockExecution_simver) HeadBranch() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for HeadBranch")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// MockExecution_simver_HeadBranch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HeadBranch'
+type MockExecution_simver_HeadBranch_Call struct {
+	*mock.Call
+}
+
+// HeadBranch is a helper method to define mock.On call
+func (_e *MockExecution_simver_Expecter) HeadBranch() *MockExecution_simver_HeadBranch_Call {
+	return &MockExecution_simver_HeadBranch_Call{Call: _e.mock.On("HeadBranch")}
+}
+
+func (_c *MockExecution_simver_HeadBranch_Call) Run(run func()) *MockExecution_simver_HeadBranch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecution_simver_HeadBranch_Call) Return(_a0 string) *MockExecution_simver_HeadBranch_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecution_simver_HeadBranch_Call) RunAndReturn(run func() string) *MockExecution_simver_HeadBranch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // HeadBranchTags provides a mock function with given fields:
 func (_m *MockExecution_simver) HeadBranchTags() simver.Tags {
 	ret := _m.Called()
@@ -114,6 +298,51 @@ func (_c *MockExecution_simver_HeadBranchTags_Call) RunAndReturn(run func() simv
 	return _c
 }
 
+// HeadCommitMessage provides a mock function with given fields:
+func (_m *MockExecution_simver) HeadCommitMessage() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for HeadCommitMessage")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// MockExecution_simver_HeadCommitMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HeadCommitMessage'
+type MockExecution_simver_HeadCommitMessage_Call struct {
+	*mock.Call
+}
+
+// HeadCommitMessage is a helper method to define mock.On call
+func (_e *MockExecution_simver_Expecter) HeadCommitMessage() *MockExecution_simver_HeadCommitMessage_Call {
+	return &MockExecution_simver_HeadCommitMessage_Call{Call: _e.mock.On("HeadCommitMessage")}
+}
+
+func (_c *MockExecution_simver_HeadCommitMessage_Call) Run(run func()) *MockExecution_simver_HeadCommitMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecution_simver_HeadCommitMessage_Call) Return(_a0 string) *MockExecution_simver_HeadCommitMessage_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecution_simver_HeadCommitMessage_Call) RunAndReturn(run func() string) *MockExecution_simver_HeadCommitMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // HeadCommitTags provides a mock function with given fields:
 func (_m *MockExecution_simver) HeadCommitTags() simver.Tags {
 	ret := _m.Called()
@@ -341,6 +570,53 @@ func (_c *MockExecution_simver_IsTargetingRoot_Call) RunAndReturn(run func() boo
 	return _c
 }
 
+// Labels provides a mock function with given fields:
+func (_m *MockExecution_simver) Labels() []string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Labels")
+	}
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// MockExecution_simver_Labels_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Labels'
+type MockExecution_simver_Labels_Call struct {
+	*mock.Call
+}
+
+// Labels is a helper method to define mock.On call
+func (_e *MockExecution_simver_Expecter) Labels() *MockExecution_simver_Labels_Call {
+	return &MockExecution_simver_Labels_Call{Call: _e.mock.On("Labels")}
+}
+
+func (_c *MockExecution_simver_Labels_Call) Run(run func()) *MockExecution_simver_Labels_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecution_simver_Labels_Call) Return(_a0 []string) *MockExecution_simver_Labels_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecution_simver_Labels_Call) RunAndReturn(run func() []string) *MockExecution_simver_Labels_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // PR provides a mock function with given fields:
 func (_m *MockExecution_simver) PR() int {
 	ret := _m.Called()
@@ -386,6 +662,53 @@ func (_c *MockExecution_simver_PR_Call) RunAndReturn(run func() int) *MockExecut
 	return _c
 }
 
+// PRTags provides a mock function with given fields:
+func (_m *MockExecution_simver) PRTags() simver.Tags {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for PRTags")
+	}
+
+	var r0 simver.Tags
+	if rf, ok := ret.Get(0).(func() simver.Tags); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(simver.Tags)
+		}
+	}
+
+	return r0
+}
+
+// MockExecution_simver_PRTags_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PRTags'
+type MockExecution_simver_PRTags_Call struct {
+	*mock.Call
+}
+
+// PRTags is a helper method to define mock.On call
+func (_e *MockExecution_simver_Expecter) PRTags() *MockExecution_simver_PRTags_Call {
+	return &MockExecution_simver_PRTags_Call{Call: _e.mock.On("PRTags")}
+}
+
+func (_c *MockExecution_simver_PRTags_Call) Run(run func()) *MockExecution_simver_PRTags_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecution_simver_PRTags_Call) Return(_a0 simver.Tags) *MockExecution_simver_PRTags_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecution_simver_PRTags_Call) RunAndReturn(run func() simver.Tags) *MockExecution_simver_PRTags_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ProvideRefs provides a mock function with given fields:
 func (_m *MockExecution_simver) ProvideRefs() simver.RefProvider {
 	ret := _m.Called()
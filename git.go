@@ -1,6 +1,9 @@
 package simver
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type GitProvider interface {
 	GetHeadRef(ctx context.Context) (string, error)
@@ -8,17 +11,34 @@ type GitProvider interface {
 	Branch(ctx context.Context) (string, error)
 	RepoName(ctx context.Context) (string, string, error)
 	Dirty(ctx context.Context) (bool, error)
+	CountCommits(ctx context.Context, from, to string) (int, error)
+	CommitTime(ctx context.Context, ref string) (time.Time, error)
+	Branches(ctx context.Context) ([]string, error)
+	CommitMessage(ctx context.Context, ref string) (string, error)
+	ChangedFiles(ctx context.Context, from, to string) ([]string, error)
+	Commits(ctx context.Context, from, to string) ([]Commit, error)
 }
 
+type Commit struct {
+	Hash    string
+	Subject string
+	Body    string
+}
+
+// DefaultRootBranch is the branch that releases are made from
+const DefaultRootBranch = "main"
+
 type PRDetails struct {
 	Number               int
+	Title                string
 	HeadBranch           string
 	BaseBranch           string
-	RootBranch           string // always main
+	RootBranch           string // always DefaultRootBranch, except for simulated pushes
 	Merged               bool
 	MergeCommit          string
 	HeadCommit           string
 	PotentialMergeCommit string
+	Labels               []string
 
 	BaseCommit string
 	RootCommit string
@@ -2,9 +2,11 @@ package gitexec
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/walteh/simver"
 	"gitlab.com/tozd/go/errors"
@@ -57,6 +59,19 @@ func BuildGitHubActionsProviders(path string, readOnly bool) (simver.GitProvider
 	return gha, git, git, gh, &GitHubActionsPullRequestResolver{gh, git}, nil
 }
 
+// BuildGitHubActionsReleaser creates releases in the repository of the current workflow run
+func BuildGitHubActionsReleaser(readOnly bool) (simver.ReleaseWriter, error) {
+	org := os.Getenv("GITHUB_REPOSITORY_OWNER")
+
+	return NewGitHubReleaser(&GitHubReleaserOpts{
+		APIURL:   os.Getenv("GITHUB_API_URL"),
+		Token:    os.Getenv("GITHUB_TOKEN"),
+		Org:      org,
+		Repo:     strings.TrimPrefix(os.Getenv("GITHUB_REPOSITORY"), org+"/"),
+		ReadOnly: readOnly,
+	})
+}
+
 type GitHubActionsPullRequestResolver struct {
 	gh  simver.PRProvider
 	git simver.GitProvider
@@ -168,3 +183,49 @@ func (me *gitProviderGithubActions) RepoName(ctx context.Context) (string, strin
 func (me *gitProviderGithubActions) Dirty(ctx context.Context) (bool, error) {
 	return me.internal.Dirty(ctx)
 }
+
+func (me *gitProviderGithubActions) CountCommits(ctx context.Context, from, to string) (int, error) {
+	return me.internal.CountCommits(ctx, from, to)
+}
+
+func (me *gitProviderGithubActions) CommitTime(ctx context.Context, ref string) (time.Time, error) {
+	return me.internal.CommitTime(ctx, ref)
+}
+
+func (me *gitProviderGithubActions) Branches(ctx context.Context) ([]string, error) {
+	return me.internal.Branches(ctx)
+}
+
+func (me *gitProviderGithubActions) CommitMessage(ctx context.Context, ref string) (string, error) {
+	return me.internal.CommitMessage(ctx, ref)
+}
+
+func (me *gitProviderGithubActions) ChangedFiles(ctx context.Context, from, to string) ([]string, error) {
+	return me.internal.ChangedFiles(ctx, from, to)
+}
+
+func (me *gitProviderGithubActions) Commits(ctx context.Context, from, to string) ([]simver.Commit, error) {
+	return me.internal.Commits(ctx, from, to)
+}
+
+// WriteGitHubActionsOutput sets a step output by appending to the file referenced by GITHUB_OUTPUT.
+// It is a no-op when not running in GitHub Actions.
+func WriteGitHubActionsOutput(name, value string) error {
+	path := os.Getenv("GITHUB_OUTPUT")
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return errors.Errorf("opening GITHUB_OUTPUT: %w", err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s=%s\n", name, value)
+	if err != nil {
+		return errors.Errorf("writing GITHUB_OUTPUT: %w", err)
+	}
+
+	return nil
+}
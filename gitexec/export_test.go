@@ -0,0 +1,8 @@
+package gitexec
+
+// the git output parsers, for table tests in gitexec_test
+var (
+	ParseTagRefs        = parseTagRefs
+	ParseRemoteTags     = parseRemoteTags
+	ParseRemoteBranches = parseRemoteBranches
+)
@@ -89,32 +89,45 @@ type githubPRCommit struct {
 	Oid string `json:"oid"`
 }
 
+type githubPRLabel struct {
+	Name string `json:"name"`
+}
+
 type githubPR struct {
-	Number               int            `json:"number"`
-	State                string         `json:"state"`
-	BaseRefName          string         `json:"baseRefName"`
-	HeadRefName          string         `json:"headRefName"`
-	MergeCommit          githubPRCommit `json:"mergeCommit"`
-	HeadRefOid           string         `json:"headRefOid"`
-	PotentialMergeCommit githubPRCommit `json:"potentialMergeCommit"`
-	MergeStateStatus     string         `json:"mergeStateStatus"`
+	Number               int             `json:"number"`
+	Title                string          `json:"title"`
+	State                string          `json:"state"`
+	BaseRefName          string          `json:"baseRefName"`
+	HeadRefName          string          `json:"headRefName"`
+	MergeCommit          githubPRCommit  `json:"mergeCommit"`
+	HeadRefOid           string          `json:"headRefOid"`
+	PotentialMergeCommit githubPRCommit  `json:"potentialMergeCommit"`
+	MergeStateStatus     string          `json:"mergeStateStatus"`
+	Labels               []githubPRLabel `json:"labels"`
 }
 
 func (me *githubPR) toPRDetails() *simver.PRDetails {
+	labels := make([]string, 0, len(me.Labels))
+	for _, l := range me.Labels {
+		labels = append(labels, l.Name)
+	}
+
 	return &simver.PRDetails{
 		Number:               me.Number,
-		RootBranch:           "main",
+		Title:                me.Title,
+		RootBranch:           simver.DefaultRootBranch,
 		HeadBranch:           me.HeadRefName,
 		BaseBranch:           me.BaseRefName,
 		Merged:               me.State == "MERGED",
 		MergeCommit:          me.MergeCommit.Oid,
 		HeadCommit:           me.HeadRefOid,
 		PotentialMergeCommit: me.PotentialMergeCommit.Oid,
+		Labels:               labels,
 	}
 }
 
 const (
-	githubPRDetailsCliQuery = `number,mergeCommit,headRefOid,state,potentialMergeCommit,mergeStateStatus,baseRefName,headRefName`
+	githubPRDetailsCliQuery = `number,mergeCommit,headRefOid,state,potentialMergeCommit,mergeStateStatus,baseRefName,headRefName,labels,title`
 )
 
 func (p *ghProvider) getRelevantPR(ctx context.Context, out []byte) (*simver.PRDetails, bool, error) {
@@ -230,7 +243,7 @@ func (p *ghProvider) getBaseCommit(ctx context.Context, dets *simver.PRDetails)
 func (p *ghProvider) getRootCommit(ctx context.Context) (string, error) {
 	zerolog.Ctx(ctx).Debug().Msg("Getting root commit")
 
-	cmd := p.gh(ctx, "api", "-H", "Accept: application/vnd.github+json", fmt.Sprintf("/repos/%s/%s/git/ref/heads/main", p.Org, p.Repo))
+	cmd := p.gh(ctx, "api", "-H", "Accept: application/vnd.github+json", fmt.Sprintf("/repos/%s/%s/git/ref/heads/%s", p.Org, p.Repo, simver.DefaultRootBranch))
 	out, err := cmd.Output()
 	if err != nil {
 		return "", errors.Errorf("gh api: %w", err)
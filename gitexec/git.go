@@ -4,7 +4,9 @@ import (
 	"context"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/walteh/simver"
@@ -183,6 +185,158 @@ func (p *gitProvider) GetHeadRef(ctx context.Context) (string, error) {
 	return res, nil
 }
 
+// CountCommits counts the commits reachable from "to" but not from "from". If "from" is empty,
+// all commits reachable from "to" are counted.
+func (p *gitProvider) CountCommits(ctx context.Context, from, to string) (int, error) {
+
+	zerolog.Ctx(ctx).Debug().Str("from", from).Str("to", to).Msg("counting commits")
+
+	rng := to
+	if from != "" {
+		rng = from + ".." + to
+	}
+
+	cmd := p.git(ctx, "rev-list", "--count", rng)
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, errors.Errorf("git rev-list --count %s: %w", rng, err)
+	}
+
+	res, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return 0, errors.Errorf("parsing commit count: %w", err)
+	}
+
+	zerolog.Ctx(ctx).Debug().Int("count", res).Msg("counted commits")
+
+	return res, nil
+}
+
+func (p *gitProvider) CommitTime(ctx context.Context, ref string) (time.Time, error) {
+
+	zerolog.Ctx(ctx).Debug().Str("ref", ref).Msg("getting commit time")
+
+	cmd := p.git(ctx, "show", "-s", "--format=%ct", ref)
+	out, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, errors.Errorf("git show -s --format=%%ct %s: %w", ref, err)
+	}
+
+	unix, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Errorf("parsing commit time: %w", err)
+	}
+
+	return time.Unix(unix, 0).UTC(), nil
+}
+
+// Branches lists the branches on origin. They are read from the remote, not from refs/remotes/origin, because
+// a shallow checkout (fetch-depth 1, the actions/checkout default) only has the branch it checked out.
+func (p *gitProvider) Branches(ctx context.Context) ([]string, error) {
+
+	zerolog.Ctx(ctx).Debug().Msg("listing branches")
+
+	cmd := p.git(ctx, "ls-remote", "--heads", "origin")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Errorf("git ls-remote --heads origin: %w", err)
+	}
+
+	branches := parseRemoteBranches(out)
+
+	zerolog.Ctx(ctx).Debug().Strs("branches", branches).Msg("listed branches")
+
+	return branches, nil
+}
+
+// parseRemoteBranches parses the output of git ls-remote --heads into branch names
+func parseRemoteBranches(out []byte) []string {
+	branches := make([]string, 0)
+	for _, line := range strings.Split(string(out), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) != 2 || !strings.HasPrefix(parts[1], "refs/heads/") {
+			continue
+		}
+		branches = append(branches, strings.TrimPrefix(parts[1], "refs/heads/"))
+	}
+	return branches
+}
+
+func (p *gitProvider) CommitMessage(ctx context.Context, ref string) (string, error) {
+
+	zerolog.Ctx(ctx).Debug().Str("ref", ref).Msg("getting commit message")
+
+	cmd := p.git(ctx, "log", "-1", "--format=%B", ref)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Errorf("git log -1 --format=%%B %s: %w", ref, err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ChangedFiles lists the files changed on "to" since it diverged from "from"
+func (p *gitProvider) ChangedFiles(ctx context.Context, from, to string) ([]string, error) {
+
+	zerolog.Ctx(ctx).Debug().Str("from", from).Str("to", to).Msg("listing changed files")
+
+	cmd := p.git(ctx, "diff", "--name-only", from+"..."+to)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Errorf("git diff --name-only %s...%s: %w", from, to, err)
+	}
+
+	files := make([]string, 0)
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		files = append(files, line)
+	}
+
+	zerolog.Ctx(ctx).Debug().Strs("files", files).Msg("listed changed files")
+
+	return files, nil
+}
+
+// Commits lists the first parent commits reachable from "to" but not from "from", newest first.
+// If "from" is empty, all commits reachable from "to" are listed.
+func (p *gitProvider) Commits(ctx context.Context, from, to string) ([]simver.Commit, error) {
+
+	zerolog.Ctx(ctx).Debug().Str("from", from).Str("to", to).Msg("listing commits")
+
+	rng := to
+	if from != "" {
+		rng = from + ".." + to
+	}
+
+	cmd := p.git(ctx, "log", "--first-parent", "--format=%H%x1f%s%x1f%b%x1e", rng)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Errorf("git log %s: %w", rng, err)
+	}
+
+	commits := make([]simver.Commit, 0)
+	for _, rec := range strings.Split(string(out), "\x1e") {
+		parts := strings.SplitN(strings.TrimSpace(rec), "\x1f", 3)
+		if len(parts) < 2 {
+			continue
+		}
+
+		c := simver.Commit{Hash: parts[0], Subject: parts[1]}
+		if len(parts) == 3 {
+			c.Body = strings.TrimSpace(parts[2])
+		}
+
+		commits = append(commits, c)
+	}
+
+	zerolog.Ctx(ctx).Debug().Int("count", len(commits)).Msg("listed commits")
+
+	return commits, nil
+}
+
 func (p *gitProvider) Dirty(ctx context.Context) (bool, error) {
 
 	zerolog.Ctx(ctx).Debug().Msg("checking dirty")
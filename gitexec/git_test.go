@@ -0,0 +1,83 @@
+package gitexec_test
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver/gitexec"
+)
+
+func TestParseRemoteBranches(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected []string
+	}{
+		{
+			name:     "empty",
+			output:   "",
+			expected: []string{},
+		},
+		{
+			name: "heads",
+			output: "1111111111111111111111111111111111111111\trefs/heads/main\n" +
+				"2222222222222222222222222222222222222222\trefs/heads/release/1.4\n",
+			expected: []string{"main", "release/1.4"},
+		},
+		{
+			name: "other refs are ignored",
+			output: "1111111111111111111111111111111111111111\trefs/heads/main\n" +
+				"3333333333333333333333333333333333333333\trefs/tags/v1.0.0\n" +
+				"garbage\n",
+			expected: []string{"main"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, gitexec.ParseRemoteBranches([]byte(tc.output)))
+		})
+	}
+}
+
+func TestBranchesOfShallowClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	ctx := context.Background()
+	root := t.TempDir()
+
+	origin := filepath.Join(root, "origin.git")
+	work := filepath.Join(root, "work")
+	clone := filepath.Join(root, "clone")
+
+	run(t, root, "init", "--quiet", "--bare", "--initial-branch=main", origin)
+	run(t, root, "clone", "--quiet", origin, work)
+	run(t, work, "checkout", "--quiet", "-b", "main")
+	run(t, work, "commit", "--quiet", "--allow-empty", "-m", "first")
+	run(t, work, "branch", "release/1.4")
+	run(t, work, "push", "--quiet", "origin", "main", "release/1.4")
+
+	// the same checkout as actions/checkout with its default fetch-depth of 1
+	run(t, root, "clone", "--quiet", "--depth=1", "--single-branch", "--branch=main", "file://"+origin, clone)
+
+	gp, err := gitexec.NewGitProvider(&gitexec.GitProviderOpts{
+		RepoPath:     clone,
+		Token:        "unused",
+		User:         "test",
+		Email:        "test@example.com",
+		TokenEnvName: "SIMVER_TEST_TOKEN",
+		Org:          "acme",
+		Repo:         "widget",
+	})
+	require.NoError(t, err)
+
+	branches, err := gp.Branches(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"main", "release/1.4"}, branches)
+}
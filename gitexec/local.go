@@ -88,7 +88,7 @@ func BuildLocalProviders(fls afero.Fs) (simver.GitProvider, simver.TagReader, si
 		return nil, nil, nil, nil, errors.Errorf("creating git provider: %w", err)
 	}
 
-	return git, git, git, &LocalPullRequestResolver{}, nil
+	return git, git, git, &LocalPullRequestResolver{git: git}, nil
 }
 
 type LocalPullRequestResolver struct {
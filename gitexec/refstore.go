@@ -0,0 +1,270 @@
+package gitexec
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/walteh/simver"
+	"gitlab.com/tozd/go/errors"
+)
+
+var (
+	_ simver.TagReader = (*refStore)(nil)
+	_ simver.TagWriter = (*refStore)(nil)
+)
+
+// refStore hides some of simver's own tags under refs/simver/ instead of refs/tags/ (see simver.TagFormat.HiddenRef),
+// so they don't show up in git tag, on the tags page or in the go module proxy. Every other tag is still a real
+// tag. Reads return both, with hidden refs turned back into tag names, so the calculation does not know the difference.
+type refStore struct {
+	*gitProvider
+	format *simver.TagFormat
+	hide   map[simver.TagRole]bool
+
+	fetch    sync.Once
+	fetchErr error
+}
+
+// WithRefStorage wraps a git backed tag reader and writer in the ref storage, hiding the tags with the given roles
+func WithRefStorage(tr simver.TagReader, tw simver.TagWriter, format *simver.TagFormat, roles ...simver.TagRole) (simver.TagReader, simver.TagWriter, error) {
+	gp, ok := tw.(*gitProvider)
+	if !ok || tr != simver.TagReader(gp) {
+		return nil, nil, errors.New("ref storage requires the git tag reader and writer")
+	}
+
+	if format == nil {
+		format = simver.DefaultTagFormat
+	}
+
+	rs := &refStore{gitProvider: gp, format: format, hide: map[simver.TagRole]bool{}}
+	for _, r := range roles {
+		rs.hide[r] = true
+	}
+
+	return rs, rs, nil
+}
+
+// WithConfiguredStorage applies the storage of the config, the tag reader and writer are returned as is for tag storage
+func WithConfiguredStorage(cfg *simver.Config, tr simver.TagReader, tw simver.TagWriter) (simver.TagReader, simver.TagWriter, error) {
+	var roles []simver.TagRole
+
+	switch {
+	case cfg == nil:
+		return tr, tw, nil
+	case cfg.Storage == simver.StorageRefs:
+		roles = []simver.TagRole{simver.TagRolePR, simver.TagRoleBase, simver.TagRoleReserved, simver.TagRoleBuild}
+	case cfg.Storage == simver.StorageReservations:
+		roles = []simver.TagRole{simver.TagRoleBase, simver.TagRoleReserved}
+	default:
+		return tr, tw, nil
+	}
+
+	format, err := cfg.TagFormat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return WithRefStorage(tr, tw, format, roles...)
+}
+
+// hiddenRef returns the ref a tag is stored under, if it is hidden
+func (p *refStore) hiddenRef(name string) (string, bool) {
+	if !p.hide[p.format.RoleOf(name[strings.LastIndex(name, "/")+1:])] {
+		return "", false
+	}
+
+	return p.format.HiddenRef(name)
+}
+
+// fetchRefs fetches the refs once, checkouts only fetch branches and tags
+func (p *refStore) fetchRefs(ctx context.Context) error {
+	p.fetch.Do(func() {
+		cmd := p.git(ctx, "fetch", "--quiet", "origin", "+"+simver.HiddenRefPrefix+"*:"+simver.HiddenRefPrefix+"*")
+		err := cmd.Run()
+		if err != nil {
+			p.fetchErr = errors.Errorf("git fetch origin %s*: %w", simver.HiddenRefPrefix, err)
+		}
+	})
+
+	return p.fetchErr
+}
+
+// refs lists the stored tags, filtered with extra git for-each-ref arguments
+func (p *refStore) refs(ctx context.Context, args ...string) (simver.Tags, error) {
+	err := p.fetchRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	args = append([]string{"for-each-ref", "--format=%(objectname) %(refname)"}, args...)
+	args = append(args, simver.HiddenRefPrefix)
+
+	out, err := p.git(ctx, args...).Output()
+	if err != nil {
+		return nil, errors.Errorf("git for-each-ref %s: %w", simver.HiddenRefPrefix, err)
+	}
+
+	return p.parseRefs(out), nil
+}
+
+// parseRefs parses "<sha> <ref>" lines, as printed by for-each-ref and ls-remote, into the tags they stand for.
+// Refs of roles that are no longer hidden are still read, so changing the storage loses nothing.
+func (p *refStore) parseRefs(out []byte) simver.Tags {
+	tags := simver.Tags{}
+
+	for _, line := range strings.Split(string(out), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			continue
+		}
+
+		name, _, ok := p.format.FromHiddenRef(parts[1])
+		if !ok {
+			continue
+		}
+
+		tags = append(tags, simver.Tag{Name: name, Ref: parts[0]})
+	}
+
+	return tags
+}
+
+func (p *refStore) TagsFromCommit(ctx context.Context, commitHash string) (simver.Tags, error) {
+	tags, err := p.gitProvider.TagsFromCommit(ctx, commitHash)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := p.refs(ctx, "--points-at", commitHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(tags, stored...), nil
+}
+
+func (p *refStore) TagsFromBranch(ctx context.Context, branch string) (simver.Tags, error) {
+	tags, err := p.gitProvider.TagsFromBranch(ctx, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := "origin/" + branch
+	if branch == "HEAD" {
+		ref = branch
+	}
+
+	stored, err := p.refs(ctx, "--merged", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(tags, stored...), nil
+}
+
+func (p *refStore) TagsFromPattern(ctx context.Context, pattern string) (simver.Tags, error) {
+	tags, err := p.gitProvider.TagsFromPattern(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := p.refs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range stored {
+		if simver.MatchGlob(pattern, t.Name) {
+			tags = append(tags, t)
+		}
+	}
+
+	return tags, nil
+}
+
+func (p *refStore) TagsFromRemote(ctx context.Context) (simver.Tags, error) {
+	tags, err := p.gitProvider.TagsFromRemote(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := p.git(ctx, "ls-remote", "origin", simver.HiddenRefPrefix+"*").Output()
+	if err != nil {
+		return nil, errors.Errorf("git ls-remote origin %s*: %w", simver.HiddenRefPrefix, err)
+	}
+
+	return append(tags, p.parseRefs(out)...), nil
+}
+
+func (p *refStore) FetchTags(ctx context.Context) (simver.Tags, error) {
+	tags, err := p.gitProvider.FetchTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := p.refs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(tags, stored...), nil
+}
+
+func (p *refStore) CreateTags(ctx context.Context, tag ...simver.Tag) error {
+	var tags, stored simver.Tags
+	for _, t := range tag {
+		if ref, ok := p.hiddenRef(t.Name); ok {
+			stored = append(stored, simver.Tag{Name: ref, Ref: t.Ref})
+		} else {
+			tags = append(tags, t)
+		}
+	}
+
+	if len(tags) > 0 {
+		err := p.gitProvider.CreateTags(ctx, tags...)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(stored) == 0 {
+		return nil
+	}
+
+	if p.ReadOnly {
+		zerolog.Ctx(ctx).Debug().Msg("read only mode, skipping ref creation")
+		return nil
+	}
+
+	push := []string{"push", "origin"}
+
+	for _, t := range stored {
+		ref := t.Name
+
+		// an empty old value makes update-ref fail if the ref exists, like git tag does
+		cmd := p.git(ctx, "update-ref", ref, t.Ref, "")
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err := cmd.Run()
+		if err != nil {
+			return errors.Wrap(err, "git update-ref "+ref+" "+t.Ref)
+		}
+
+		push = append(push, ref)
+	}
+
+	cmd := p.git(ctx, push...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if err != nil {
+		return errors.Errorf("git push origin %s: %w", simver.HiddenRefPrefix, err)
+	}
+
+	zerolog.Ctx(ctx).Debug().Int("count", len(stored)).Msg("refs created")
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package gitexec_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+	"github.com/walteh/simver/gitexec"
+)
+
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+
+	return strings.TrimSpace(string(out))
+}
+
+func refStorage(t *testing.T, dir string, storage string) (simver.TagReader, simver.TagWriter) {
+	t.Helper()
+
+	gp, err := gitexec.NewGitProvider(&gitexec.GitProviderOpts{
+		RepoPath:     dir,
+		Token:        "unused",
+		User:         "test",
+		Email:        "test@example.com",
+		TokenEnvName: "SIMVER_TEST_TOKEN",
+		Org:          "acme",
+		Repo:         "widget",
+	})
+	require.NoError(t, err)
+
+	cfg := simver.DefaultConfig()
+	cfg.Storage = storage
+
+	tr, tw, err := gitexec.WithConfiguredStorage(cfg, gp, gp)
+	require.NoError(t, err)
+
+	return tr, tw
+}
+
+func names(tags simver.Tags) []string {
+	out := []string{}
+	for _, t := range tags {
+		out = append(out, t.Name)
+	}
+	return out
+}
+
+func TestRefStorage(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	ctx := context.Background()
+	root := t.TempDir()
+
+	origin := filepath.Join(root, "origin.git")
+	writer := filepath.Join(root, "writer")
+	reader := filepath.Join(root, "reader")
+
+	run(t, root, "init", "--quiet", "--bare", "--initial-branch=main", origin)
+	run(t, root, "clone", "--quiet", origin, writer)
+	run(t, writer, "checkout", "--quiet", "-b", "main")
+	run(t, writer, "commit", "--quiet", "--allow-empty", "-m", "first")
+	run(t, writer, "push", "--quiet", "origin", "main")
+
+	commit := run(t, writer, "rev-parse", "HEAD")
+
+	_, tw := refStorage(t, writer, simver.StorageRefs)
+
+	err := tw.CreateTags(ctx,
+		simver.Tag{Name: "v0.1.0", Ref: commit},
+		simver.Tag{Name: "v0.2.0-pr2+1", Ref: commit},
+		simver.Tag{Name: "v0.2.0-pr2+base", Ref: commit},
+		simver.Tag{Name: "v0.2.0-reserved", Ref: commit},
+	)
+	require.NoError(t, err)
+
+	// only the release is a real tag, on both ends
+	assert.Equal(t, "v0.1.0", run(t, writer, "tag", "--list"))
+	assert.Equal(t, "v0.1.0", run(t, origin, "tag", "--list"))
+
+	// a fresh checkout only has the tags, the refs are fetched on the first read
+	run(t, root, "clone", "--quiet", origin, reader)
+
+	tr, _ := refStorage(t, reader, simver.StorageRefs)
+
+	assert.ElementsMatch(t, []string{"refs/simver/pr/2/1/v0.2.0", "refs/simver/base/2/v0.2.0", "refs/simver/reserved/v0.2.0"},
+		strings.Fields(run(t, origin, "for-each-ref", "--format=%(refname)", "refs/simver/")))
+
+	all := []string{"v0.1.0", "v0.2.0-pr2+1", "v0.2.0-pr2+base", "v0.2.0-reserved"}
+
+	tags, err := tr.TagsFromCommit(ctx, commit)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, all, names(tags))
+
+	tags, err = tr.TagsFromBranch(ctx, "main")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, all, names(tags))
+
+	// same as git tag --list, the pr pattern also matches the base tag
+	tags, err = tr.TagsFromPattern(ctx, simver.DefaultTagFormat.PRPattern(2))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"v0.2.0-pr2+1", "v0.2.0-pr2+base"}, names(tags))
+
+	tags, err = tr.TagsFromRemote(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, all, names(tags))
+	for _, tag := range tags {
+		assert.Equal(t, commit, tag.Ref, tag.Name)
+	}
+}
+
+func TestReservationStorage(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	ctx := context.Background()
+	root := t.TempDir()
+
+	origin := filepath.Join(root, "origin.git")
+	writer := filepath.Join(root, "writer")
+
+	run(t, root, "init", "--quiet", "--bare", "--initial-branch=main", origin)
+	run(t, root, "clone", "--quiet", origin, writer)
+	run(t, writer, "checkout", "--quiet", "-b", "main")
+	run(t, writer, "commit", "--quiet", "--allow-empty", "-m", "first")
+	run(t, writer, "push", "--quiet", "origin", "main")
+
+	commit := run(t, writer, "rev-parse", "HEAD")
+
+	// a reservation from before the switch is still read
+	run(t, writer, "tag", "v0.1.0-reserved", commit)
+
+	tr, tw := refStorage(t, writer, simver.StorageReservations)
+
+	err := tw.CreateTags(ctx,
+		simver.Tag{Name: "v0.2.0-pr2+1", Ref: commit},
+		simver.Tag{Name: "v0.2.0-pr2+base", Ref: commit},
+		simver.Tag{Name: "v0.2.0-reserved", Ref: commit},
+	)
+	require.NoError(t, err)
+
+	// only the bookkeeping is hidden, pr builds are still tags
+	assert.ElementsMatch(t, []string{"v0.1.0-reserved", "v0.2.0-pr2+1"}, strings.Fields(run(t, writer, "tag", "--list")))
+	assert.ElementsMatch(t, []string{"refs/simver/base/2/v0.2.0", "refs/simver/reserved/v0.2.0"},
+		strings.Fields(run(t, origin, "for-each-ref", "--format=%(refname)", "refs/simver/")))
+
+	tags, err := tr.TagsFromBranch(ctx, "main")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"v0.1.0-reserved", "v0.2.0-pr2+1", "v0.2.0-pr2+base", "v0.2.0-reserved"}, names(tags))
+
+	highest, ok := tags.Versions().WithRole(simver.TagRoleReserved).Highest()
+	require.True(t, ok)
+	assert.Equal(t, "v0.2.0-reserved", highest.String())
+}
+
+func TestWithConfiguredStorageDefaultsToTags(t *testing.T) {
+	gp, err := gitexec.NewGitProvider(&gitexec.GitProviderOpts{
+		RepoPath:     t.TempDir(),
+		User:         "test",
+		Email:        "test@example.com",
+		TokenEnvName: "SIMVER_TEST_TOKEN",
+		Org:          "acme",
+		Repo:         "widget",
+		ReadOnly:     true,
+	})
+	require.NoError(t, err)
+
+	tr, tw, err := gitexec.WithConfiguredStorage(simver.DefaultConfig(), gp, gp)
+	require.NoError(t, err)
+	assert.Same(t, gp, tr)
+	assert.Same(t, gp, tw)
+}
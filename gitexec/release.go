@@ -0,0 +1,201 @@
+package gitexec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/walteh/simver"
+	"gitlab.com/tozd/go/errors"
+)
+
+var _ simver.ReleaseWriter = (*githubReleaser)(nil)
+
+var (
+	ErrGitHubAPI = errors.New("simver.ErrGitHubAPI")
+)
+
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+type githubReleaser struct {
+	Client   *http.Client
+	APIURL   string
+	Token    string
+	Org      string
+	Repo     string
+	ReadOnly bool
+}
+
+type GitHubReleaserOpts struct {
+	Client   *http.Client
+	APIURL   string // defaults to https://api.github.com
+	Token    string
+	Org      string
+	Repo     string
+	ReadOnly bool
+}
+
+func NewGitHubReleaser(opts *GitHubReleaserOpts) (simver.ReleaseWriter, error) {
+	if !opts.ReadOnly && opts.Token == "" {
+		return nil, errors.Wrap(ErrGitHubAPI, "token is required for read/write")
+	}
+
+	if opts.Org == "" {
+		return nil, errors.Wrap(ErrGitHubAPI, "org is required")
+	}
+
+	if opts.Repo == "" {
+		return nil, errors.Wrap(ErrGitHubAPI, "repo is required")
+	}
+
+	if opts.APIURL == "" {
+		opts.APIURL = DefaultGitHubAPIURL
+	}
+
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	return &githubReleaser{
+		Client:   opts.Client,
+		APIURL:   strings.TrimSuffix(opts.APIURL, "/"),
+		Token:    opts.Token,
+		Org:      opts.Org,
+		Repo:     opts.Repo,
+		ReadOnly: opts.ReadOnly,
+	}, nil
+}
+
+type githubRelease struct {
+	TagName         string `json:"tag_name"`
+	TargetCommitish string `json:"target_commitish,omitempty"`
+	Name            string `json:"name"`
+	Body            string `json:"body"`
+	Draft           bool   `json:"draft"`
+	Prerelease      bool   `json:"prerelease"`
+}
+
+// https://docs.github.com/en/rest/releases/releases#create-a-release
+func (p *githubReleaser) CreateRelease(ctx context.Context, rel *simver.Release) error {
+	ctx = zerolog.Ctx(ctx).With().Str("tag", rel.Tag).Logger().WithContext(ctx)
+
+	if p.ReadOnly {
+		zerolog.Ctx(ctx).Debug().Msg("read only mode, skipping release creation")
+		return nil
+	}
+
+	exists, err := p.releaseExists(ctx, rel.Tag, rel.Draft)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		zerolog.Ctx(ctx).Debug().Msg("release already exists, skipping")
+		return nil
+	}
+
+	body, err := json.Marshal(&githubRelease{
+		TagName:         rel.Tag,
+		TargetCommitish: rel.Commit,
+		Name:            rel.Name,
+		Body:            rel.Notes,
+		Draft:           rel.Draft,
+		Prerelease:      rel.Prerelease,
+	})
+	if err != nil {
+		return errors.Errorf("json marshal: %w", err)
+	}
+
+	resp, err := p.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/%s/releases", p.Org, p.Repo), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return p.errorFrom(resp)
+	}
+
+	return nil
+}
+
+// releasesPerPage is the largest page size the releases api allows
+const releasesPerPage = 100
+
+// releaseExists makes creating a release idempotent, so a rerun of the same workflow does not fail. Published
+// releases are looked up by their tag. Drafts have no tag yet and are not found that way, so for a draft the
+// most recent releases are searched instead, which is where the draft of an earlier run is.
+//
+// https://docs.github.com/en/rest/releases/releases#get-a-release-by-tag-name
+// https://docs.github.com/en/rest/releases/releases#list-releases
+func (p *githubReleaser) releaseExists(ctx context.Context, tag string, draft bool) (bool, error) {
+	path := fmt.Sprintf("/repos/%s/%s/releases/tags/%s", p.Org, p.Repo, url.PathEscape(tag))
+	if draft {
+		path = fmt.Sprintf("/repos/%s/%s/releases?per_page=%d", p.Org, p.Repo, releasesPerPage)
+	}
+
+	resp, err := p.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case !draft && resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode != http.StatusOK:
+		return false, p.errorFrom(resp)
+	case !draft:
+		return true, nil
+	}
+
+	var releases []githubRelease
+	err = json.NewDecoder(resp.Body).Decode(&releases)
+	if err != nil {
+		return false, errors.Errorf("decoding releases: %w", err)
+	}
+
+	for _, rel := range releases {
+		if rel.TagName == tag && rel.Draft {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (p *githubReleaser) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	zerolog.Ctx(ctx).Debug().Str("method", method).Str("path", path).Msg("calling github api")
+
+	req, err := http.NewRequestWithContext(ctx, method, p.APIURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Errorf("building request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, errors.Errorf("%s %s: %w", method, path, err)
+	}
+
+	return resp, nil
+}
+
+func (p *githubReleaser) errorFrom(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return errors.Wrapf(ErrGitHubAPI, "%s %s: %s: %s", resp.Request.Method, resp.Request.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+}
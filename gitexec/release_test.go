@@ -0,0 +1,172 @@
+package gitexec_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+	"github.com/walteh/simver/gitexec"
+)
+
+type fakeRelease struct {
+	TagName string `json:"tag_name"`
+	Draft   bool   `json:"draft"`
+}
+
+type fakeReleaseAPI struct {
+	existing []fakeRelease
+	created  []map[string]any
+	status   int
+	auth     []string
+	gets     int
+}
+
+func (me *fakeReleaseAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	me.auth = append(me.auth, r.Header.Get("Authorization"))
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/repos/acme/widget/releases/tags/"):
+		me.gets++
+		tag := strings.TrimPrefix(r.URL.Path, "/repos/acme/widget/releases/tags/")
+		for _, rel := range me.existing {
+			// drafts are not found by their tag
+			if rel.TagName == tag && !rel.Draft {
+				_ = json.NewEncoder(w).Encode(rel)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/widget/releases":
+		me.gets++
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		_ = json.NewEncoder(w).Encode(me.existing[:min(perPage, len(me.existing))])
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/widget/releases":
+		if me.status != 0 {
+			w.WriteHeader(me.status)
+			_, _ = w.Write([]byte(`{"message": "Validation Failed"}`))
+			return
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		me.created = append(me.created, body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 2}`))
+	default:
+		w.WriteHeader(http.StatusTeapot)
+	}
+}
+
+func newReleaser(t *testing.T, api *fakeReleaseAPI, readOnly bool) simver.ReleaseWriter {
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	rw, err := gitexec.NewGitHubReleaser(&gitexec.GitHubReleaserOpts{
+		Client:   srv.Client(),
+		APIURL:   srv.URL,
+		Token:    "secret",
+		Org:      "acme",
+		Repo:     "widget",
+		ReadOnly: readOnly,
+	})
+	require.NoError(t, err)
+
+	return rw
+}
+
+func TestGitHubReleaser(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates the release", func(t *testing.T) {
+		api := &fakeReleaseAPI{}
+		rw := newReleaser(t, api, false)
+
+		err := rw.CreateRelease(ctx, &simver.Release{
+			Tag:        "v1.3.0-pr4+2",
+			Commit:     "abc123",
+			Name:       "v1.3.0-pr4+2",
+			Notes:      "## v1.3.0-pr4+2\n",
+			Draft:      true,
+			Prerelease: true,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, api.created, 1)
+		assert.Equal(t, map[string]any{
+			"tag_name":         "v1.3.0-pr4+2",
+			"target_commitish": "abc123",
+			"name":             "v1.3.0-pr4+2",
+			"body":             "## v1.3.0-pr4+2\n",
+			"draft":            true,
+			"prerelease":       true,
+		}, api.created[0])
+		assert.Equal(t, []string{"Bearer secret", "Bearer secret"}, api.auth)
+	})
+
+	t.Run("skips existing releases", func(t *testing.T) {
+		api := &fakeReleaseAPI{existing: []fakeRelease{{TagName: "v1.2.0"}, {TagName: "v1.3.0"}}}
+		rw := newReleaser(t, api, false)
+
+		err := rw.CreateRelease(ctx, &simver.Release{Tag: "v1.3.0", Name: "v1.3.0"})
+		require.NoError(t, err)
+		assert.Empty(t, api.created)
+	})
+
+	t.Run("skips existing drafts", func(t *testing.T) {
+		api := &fakeReleaseAPI{existing: []fakeRelease{{TagName: "v1.3.0-pr4+2", Draft: true}}}
+		rw := newReleaser(t, api, false)
+
+		err := rw.CreateRelease(ctx, &simver.Release{Tag: "v1.3.0-pr4+2", Name: "v1.3.0-pr4+2", Draft: true})
+		require.NoError(t, err)
+		assert.Empty(t, api.created)
+	})
+
+	t.Run("looks up one release", func(t *testing.T) {
+		api := &fakeReleaseAPI{}
+		for i := 150; i > 0; i-- {
+			api.existing = append(api.existing, fakeRelease{TagName: fmt.Sprintf("v1.%d.0", i)})
+		}
+		rw := newReleaser(t, api, false)
+
+		err := rw.CreateRelease(ctx, &simver.Release{Tag: "v1.1.0", Name: "v1.1.0"})
+		require.NoError(t, err)
+		assert.Empty(t, api.created)
+		assert.Equal(t, 1, api.gets)
+
+		err = rw.CreateRelease(ctx, &simver.Release{Tag: "v1.151.0", Name: "v1.151.0"})
+		require.NoError(t, err)
+		assert.Len(t, api.created, 1)
+	})
+
+	t.Run("surfaces api errors", func(t *testing.T) {
+		api := &fakeReleaseAPI{status: http.StatusUnprocessableEntity}
+		rw := newReleaser(t, api, false)
+
+		err := rw.CreateRelease(ctx, &simver.Release{Tag: "v1.3.0", Name: "v1.3.0"})
+		require.ErrorIs(t, err, gitexec.ErrGitHubAPI)
+		assert.Contains(t, err.Error(), "422")
+		assert.Contains(t, err.Error(), "Validation Failed")
+	})
+
+	t.Run("read only", func(t *testing.T) {
+		api := &fakeReleaseAPI{}
+		rw := newReleaser(t, api, true)
+
+		err := rw.CreateRelease(ctx, &simver.Release{Tag: "v1.3.0", Name: "v1.3.0"})
+		require.NoError(t, err)
+		assert.Empty(t, api.auth)
+	})
+}
+
+func TestNewGitHubReleaserRequiresToken(t *testing.T) {
+	_, err := gitexec.NewGitHubReleaser(&gitexec.GitHubReleaserOpts{Org: "acme", Repo: "widget"})
+	require.ErrorIs(t, err, gitexec.ErrGitHubAPI)
+}
@@ -60,12 +60,35 @@ func (p *gitProvider) TagsFromBranch(ctx context.Context, branch string) (simver
 		return nil, errors.New("branch is required")
 	}
 
-	cmd := p.git(ctx, "tag", "--merged", "origin/"+branch, "--format='{\"sha\":\"%(objectname)\",\"type\": \"%(objecttype)\", \"ref\": \"%(refname)\"}'")
+	// HEAD is used by local executions, where the current branch might not exist on origin
+	ref := "origin/" + branch
+	if branch == "HEAD" {
+		ref = branch
+	}
+
+	cmd := p.git(ctx, "tag", "--merged", ref, tagRefFormat)
 	out, err := cmd.Output()
 	if err != nil {
-		return nil, errors.Wrap(err, "git tag --merged origin/"+branch)
+		return nil, errors.Wrap(err, "git tag --merged "+ref)
+	}
+
+	tags, err := parseTagRefs(out)
+	if err != nil {
+		return nil, err
 	}
 
+	zerolog.Ctx(ctx).Debug().Int("tags_len", len(tags)).Dur("dur", time.Since(start)).Msg("got tags from branch")
+
+	// tags = tags.ExtractCommitRefs()
+
+	return tags, nil
+
+}
+
+const tagRefFormat = "--format='{\"sha\":\"%(objectname)\",\"type\": \"%(objecttype)\", \"ref\": \"%(refname)\"}'"
+
+// parseTagRefs parses the output of a git tag command that was run with tagRefFormat
+func parseTagRefs(out []byte) (simver.Tags, error) {
 	lines := strings.Split(string(out), "\n")
 
 	var tags simver.Tags
@@ -85,7 +108,7 @@ func (p *gitProvider) TagsFromBranch(ctx context.Context, branch string) (simver
 		line = strings.TrimPrefix(line, "'")
 		line = strings.TrimSuffix(line, "'")
 
-		err = json.Unmarshal([]byte(line), &dat)
+		err := json.Unmarshal([]byte(line), &dat)
 		if err != nil {
 			return nil, errors.Errorf("json unmarshal: %w", err)
 		}
@@ -107,12 +130,91 @@ func (p *gitProvider) TagsFromBranch(ctx context.Context, branch string) (simver
 		tags = append(tags, simver.Tag{Name: name, Ref: dat.Sha})
 	}
 
-	zerolog.Ctx(ctx).Debug().Int("tags_len", len(tags)).Dur("dur", time.Since(start)).Msg("got tags from branch")
+	return tags, nil
+}
 
-	// tags = tags.ExtractCommitRefs()
+func (p *gitProvider) TagsFromPattern(ctx context.Context, pattern string) (simver.Tags, error) {
+
+	start := time.Now()
+
+	ctx = zerolog.Ctx(ctx).With().Str("pattern", pattern).Logger().WithContext(ctx)
+
+	zerolog.Ctx(ctx).Debug().Msg("getting tags from pattern")
+
+	if pattern == "" {
+		return nil, errors.New("pattern is required")
+	}
+
+	cmd := p.git(ctx, "tag", "--list", pattern, tagRefFormat)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "git tag --list "+pattern)
+	}
+
+	tags, err := parseTagRefs(out)
+	if err != nil {
+		return nil, err
+	}
+
+	zerolog.Ctx(ctx).Debug().Int("tags_len", len(tags)).Dur("dur", time.Since(start)).Msg("got tags from pattern")
+
+	return tags, nil
+}
+
+// TagsFromRemote lists the tags on origin without fetching them. Annotated tags
+// are resolved to the commit they point to.
+func (p *gitProvider) TagsFromRemote(ctx context.Context) (simver.Tags, error) {
+
+	start := time.Now()
+
+	zerolog.Ctx(ctx).Debug().Msg("listing remote tags")
+
+	cmd := p.git(ctx, "ls-remote", "--tags", "origin")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Errorf("git ls-remote --tags origin: %w", err)
+	}
+
+	tags := parseRemoteTags(out)
+
+	zerolog.Ctx(ctx).Debug().Int("tags_len", len(tags)).Dur("duration", time.Since(start)).Msg("listed remote tags")
 
 	return tags, nil
+}
+
+// parseRemoteTags parses the output of git ls-remote --tags. Annotated tags are listed twice, as the tag
+// object and peeled (with a ^{} suffix) as the commit it points to, and the commit wins.
+func parseRemoteTags(out []byte) simver.Tags {
+	refs := make(map[string]string)
+	order := []string{}
+
+	for _, line := range strings.Split(string(out), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			continue
+		}
+
+		name := strings.TrimPrefix(parts[1], "refs/tags/")
+
+		// peeled entries point at the commit of an annotated tag and take precedence
+		peeled := strings.HasSuffix(name, "^{}")
+		name = strings.TrimSuffix(name, "^{}")
+
+		if _, ok := refs[name]; !ok {
+			order = append(order, name)
+		} else if !peeled {
+			continue
+		}
+
+		refs[name] = parts[0]
+	}
 
+	tags := make(simver.Tags, 0, len(order))
+	for _, name := range order {
+		tags = append(tags, simver.Tag{Name: name, Ref: refs[name]})
+	}
+
+	return tags
 }
 
 func (p *gitProvider) FetchTags(ctx context.Context) (simver.Tags, error) {
@@ -190,3 +292,42 @@ func (p *gitProvider) CreateTags(ctx context.Context, tag ...simver.Tag) error {
 
 	return nil
 }
+
+func (p *gitProvider) MoveTags(ctx context.Context, tag ...simver.Tag) error {
+
+	if p.ReadOnly {
+		zerolog.Ctx(ctx).Debug().Msg("read only mode, skipping tag move")
+		return nil
+	}
+
+	if len(tag) == 0 {
+		return nil
+	}
+
+	refs := []string{"push", "--force", "origin"}
+
+	for _, t := range tag {
+		cmd := p.git(ctx, "tag", "--force", t.Name, t.Ref)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err := cmd.Run()
+		if err != nil {
+			return errors.Wrap(err, "git tag --force "+t.Name+" "+t.Ref)
+		}
+
+		refs = append(refs, "refs/tags/"+t.Name)
+	}
+
+	// only the moved tags are force pushed, every other tag is left alone
+	cmd := p.git(ctx, refs...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if err != nil {
+		return errors.Errorf("git push --force origin: %w", err)
+	}
+
+	zerolog.Ctx(ctx).Debug().Int("count", len(tag)).Msg("tags moved")
+
+	return nil
+}
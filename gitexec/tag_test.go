@@ -0,0 +1,144 @@
+package gitexec_test
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+	"github.com/walteh/simver/gitexec"
+)
+
+func TestParseRemoteTags(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected simver.Tags
+	}{
+		{
+			name:     "empty",
+			output:   "",
+			expected: simver.Tags{},
+		},
+		{
+			name: "lightweight tags",
+			output: "1111111111111111111111111111111111111111\trefs/tags/v1.2.3\n" +
+				"2222222222222222222222222222222222222222\trefs/tags/v1.3.0-pr4+2\n",
+			expected: simver.Tags{
+				simver.Tag{Name: "v1.2.3", Ref: "1111111111111111111111111111111111111111"},
+				simver.Tag{Name: "v1.3.0-pr4+2", Ref: "2222222222222222222222222222222222222222"},
+			},
+		},
+		{
+			name: "annotated tag resolves to the peeled commit",
+			output: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa\trefs/tags/v2.0.0\n" +
+				"3333333333333333333333333333333333333333\trefs/tags/v2.0.0^{}\n",
+			expected: simver.Tags{
+				simver.Tag{Name: "v2.0.0", Ref: "3333333333333333333333333333333333333333"},
+			},
+		},
+		{
+			name: "peeled entry before the tag object",
+			output: "3333333333333333333333333333333333333333\trefs/tags/v2.0.0^{}\n" +
+				"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa\trefs/tags/v2.0.0\n",
+			expected: simver.Tags{
+				simver.Tag{Name: "v2.0.0", Ref: "3333333333333333333333333333333333333333"},
+			},
+		},
+		{
+			name: "module prefixes and junk lines",
+			output: "warning: redirecting to https://github.com/acme/widget.git/\n" +
+				"\n" +
+				"4444444444444444444444444444444444444444\trefs/tags/tools/v0.1.0\n",
+			expected: simver.Tags{
+				simver.Tag{Name: "tools/v0.1.0", Ref: "4444444444444444444444444444444444444444"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, gitexec.ParseRemoteTags([]byte(tc.output)))
+		})
+	}
+}
+
+func TestParseTagRefs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected simver.Tags
+		err      bool
+	}{
+		{
+			name: "commits",
+			output: `'{"sha":"1111111111111111111111111111111111111111","type": "commit", "ref": "refs/tags/v1.2.3"}'` + "\n" +
+				`'{"sha":"2222222222222222222222222222222222222222","type": "commit", "ref": "refs/tags/v1.3.0-pr4+base"}'` + "\n",
+			expected: simver.Tags{
+				simver.Tag{Name: "v1.2.3", Ref: "1111111111111111111111111111111111111111"},
+				simver.Tag{Name: "v1.3.0-pr4+base", Ref: "2222222222222222222222222222222222222222"},
+			},
+		},
+		{
+			name:   "annotated tags are skipped",
+			output: `'{"sha":"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa","type": "tag", "ref": "refs/tags/v2.0.0"}'` + "\n",
+		},
+		{
+			name:   "module prefixes are skipped",
+			output: `'{"sha":"4444444444444444444444444444444444444444","type": "commit", "ref": "refs/tags/tools/v0.1.0"}'` + "\n",
+		},
+		{
+			name:   "invalid json",
+			output: "v1.2.3\n",
+			err:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tags, err := gitexec.ParseTagRefs([]byte(tc.output))
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, tags)
+		})
+	}
+}
+
+func TestTagsFromRemote(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	ctx := context.Background()
+	root := t.TempDir()
+
+	origin := filepath.Join(root, "origin.git")
+	clone := filepath.Join(root, "clone")
+
+	run(t, root, "init", "--quiet", "--bare", "--initial-branch=main", origin)
+	run(t, root, "clone", "--quiet", origin, clone)
+	run(t, clone, "checkout", "--quiet", "-b", "main")
+	run(t, clone, "commit", "--quiet", "--allow-empty", "-m", "first")
+	first := run(t, clone, "rev-parse", "HEAD")
+	run(t, clone, "commit", "--quiet", "--allow-empty", "-m", "second")
+	second := run(t, clone, "rev-parse", "HEAD")
+
+	run(t, clone, "tag", "v1.0.0", first)
+	run(t, clone, "tag", "--annotate", "-m", "release", "v1.1.0", second)
+	run(t, clone, "push", "--quiet", "origin", "main", "--tags")
+
+	tr, _ := refStorage(t, clone, "")
+
+	tags, err := tr.TagsFromRemote(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, simver.Tags{
+		simver.Tag{Name: "v1.0.0", Ref: first},
+		simver.Tag{Name: "v1.1.0", Ref: second},
+	}, tags)
+}
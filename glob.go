@@ -0,0 +1,47 @@
+package simver
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchGlob matches a slash separated file name against a glob. On top of path.Match, "**" matches any
+// number of directories, and patterns without a slash match the base name at any depth (like .gitignore).
+func MatchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+
+	return matchSegments(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+func validGlob(pattern string) bool {
+	_, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), "")
+	return err == nil
+}
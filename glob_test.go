@@ -0,0 +1,33 @@
+package simver_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walteh/simver"
+)
+
+func TestMatchGlob(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "*.md", name: "README.md", expected: true},
+		{pattern: "*.md", name: "docs/guide/intro.md", expected: true},
+		{pattern: "*.md", name: "main.go", expected: false},
+		{pattern: "docs/**", name: "docs/guide/intro.md", expected: true},
+		{pattern: "docs/**", name: "docs", expected: true},
+		{pattern: "docs/**", name: "src/docs/intro.md", expected: false},
+		{pattern: "**/testdata/*", name: "pkg/a/testdata/x.json", expected: true},
+		{pattern: "cmd/*/main.go", name: "cmd/simver/main.go", expected: true},
+		{pattern: "cmd/*/main.go", name: "cmd/simver/calc.go", expected: false},
+		{pattern: "/go.mod", name: "go.mod", expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" "+tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, simver.MatchGlob(tc.pattern, tc.name))
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package simver
+
+import (
+	"strconv"
+	"strings"
+)
+
+// HiddenRefPrefix is where hidden tags are stored, outside of refs/tags/ so that git tag, the GitHub tags
+// page and the go module proxy don't see them
+const HiddenRefPrefix = "refs/simver/"
+
+// HiddenRef returns the ref a pr, base, reserved or build tag is hidden under. The layout does not depend
+// on the tag format, so switching templates keeps hidden tags readable:
+//
+//	v1.3.0-reserved  refs/simver/reserved/v1.3.0
+//	v1.3.0-pr4+base  refs/simver/base/4/v1.3.0
+//	v1.3.0-pr4+2     refs/simver/pr/4/2/v1.3.0
+//	v1.3.0+build.5   refs/simver/build/5/v1.3.0
+func (f *TagFormat) HiddenRef(name string) (string, bool) {
+	prefix, rest := "", name
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		prefix, rest = name[:idx+1], name[idx+1:]
+	}
+
+	role, pr, build := f.parse(rest)
+	if role != TagRolePR && role != TagRoleBase && role != TagRoleReserved && role != TagRoleBuild {
+		return "", false
+	}
+
+	core := prefix + rest[:strings.IndexAny(rest, "-+")]
+
+	switch role {
+	case TagRoleReserved:
+		return HiddenRefPrefix + "reserved/" + core, true
+	case TagRoleBase:
+		return HiddenRefPrefix + "base/" + strconv.Itoa(pr) + "/" + core, true
+	case TagRolePR:
+		return HiddenRefPrefix + "pr/" + strconv.Itoa(pr) + "/" + strconv.Itoa(build) + "/" + core, true
+	default:
+		return HiddenRefPrefix + "build/" + strconv.Itoa(build) + "/" + core, true
+	}
+}
+
+// FromHiddenRef turns a hidden ref back into the tag name it stands for, in this format
+func (f *TagFormat) FromHiddenRef(ref string) (string, TagRole, bool) {
+	rest, ok := strings.CutPrefix(ref, HiddenRefPrefix)
+	if !ok {
+		return "", TagRoleInvalid, false
+	}
+
+	role, rest, _ := strings.Cut(rest, "/")
+
+	// the numbers come first, the (possibly prefixed) version is the rest
+	numbers := func(n int) ([]int, string, bool) {
+		out := make([]int, n)
+		for i := range out {
+			var part string
+			part, rest, _ = strings.Cut(rest, "/")
+			num, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, "", false
+			}
+			out[i] = num
+		}
+		return out, rest, true
+	}
+
+	var name string
+
+	switch TagRole(role) {
+	case TagRoleReserved:
+		name = f.ReservedTag(rest)
+	case TagRoleBase:
+		n, core, ok := numbers(1)
+		if !ok {
+			return "", TagRoleInvalid, false
+		}
+		name = f.BaseTag(core, n[0])
+	case TagRolePR:
+		n, core, ok := numbers(2)
+		if !ok {
+			return "", TagRoleInvalid, false
+		}
+		name = f.PRTag(core, n[0], n[1])
+	case TagRoleBuild:
+		n, core, ok := numbers(1)
+		if !ok {
+			return "", TagRoleInvalid, false
+		}
+		name = f.BuildTag(core, n[0])
+	default:
+		return "", TagRoleInvalid, false
+	}
+
+	if v, err := ParseVersion(name); err != nil || f.RoleOf(name[len(v.Prefix):]) != TagRole(role) {
+		return "", TagRoleInvalid, false
+	}
+
+	return name, TagRole(role), true
+}
@@ -0,0 +1,57 @@
+package simver_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walteh/simver"
+)
+
+func TestHiddenRef(t *testing.T) {
+	custom := simver.MustNewTagFormat("-pr.{pr}.{build}", "-pr.{pr}.base", "-rsv", "+b{build}")
+
+	testCases := []struct {
+		name   string
+		ref    string
+		role   simver.TagRole
+		custom string
+	}{
+		{name: "v1.3.0-reserved", ref: "refs/simver/reserved/v1.3.0", role: simver.TagRoleReserved, custom: "v1.3.0-rsv"},
+		{name: "v1.3.0-pr4+base", ref: "refs/simver/base/4/v1.3.0", role: simver.TagRoleBase, custom: "v1.3.0-pr.4.base"},
+		{name: "v1.3.0-pr4+2", ref: "refs/simver/pr/4/2/v1.3.0", role: simver.TagRolePR, custom: "v1.3.0-pr.4.2"},
+		{name: "v1.3.0+build.5", ref: "refs/simver/build/5/v1.3.0", role: simver.TagRoleBuild, custom: "v1.3.0+b5"},
+		{name: "tools/v0.2.0-reserved", ref: "refs/simver/reserved/tools/v0.2.0", role: simver.TagRoleReserved, custom: "tools/v0.2.0-rsv"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, ok := simver.DefaultTagFormat.HiddenRef(tc.name)
+			assert.True(t, ok)
+			assert.Equal(t, tc.ref, ref)
+
+			name, role, ok := simver.DefaultTagFormat.FromHiddenRef(tc.ref)
+			assert.True(t, ok)
+			assert.Equal(t, tc.name, name)
+			assert.Equal(t, tc.role, role)
+
+			// the layout does not depend on the format
+			ref, ok = custom.HiddenRef(tc.custom)
+			assert.True(t, ok)
+			assert.Equal(t, tc.ref, ref)
+
+			name, _, ok = custom.FromHiddenRef(tc.ref)
+			assert.True(t, ok)
+			assert.Equal(t, tc.custom, name)
+		})
+	}
+
+	for _, name := range []string{"v1.3.0", "v2.0.0-rc.1", "latest"} {
+		_, ok := simver.DefaultTagFormat.HiddenRef(name)
+		assert.False(t, ok, name)
+	}
+
+	for _, ref := range []string{"refs/tags/v1.3.0", "refs/simver/pr/x/1/v1.3.0", "refs/simver/other/v1.3.0", "refs/simver/reserved/nope"} {
+		_, _, ok := simver.DefaultTagFormat.FromHiddenRef(ref)
+		assert.False(t, ok, ref)
+	}
+}
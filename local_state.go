@@ -2,6 +2,8 @@ package simver
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"gitlab.com/tozd/go/errors"
 )
@@ -9,13 +11,15 @@ import (
 var _ Execution = &LocalProjectState{}
 
 type LocalProjectState struct {
-	Commit string
-	Branch string
-	Tags   Tags
-	Dirty  bool
+	Commit          string
+	Branch          string
+	Tags            Tags
+	Dirty           bool
+	CommitsSinceTag int
+	CommitTime      time.Time
 }
 
-func NewLocalProjectState(ctx context.Context, gp GitProvider, tr TagReader) (Execution, error) {
+func NewLocalProjectState(ctx context.Context, gp GitProvider, tr TagReader) (*LocalProjectState, error) {
 
 	commit, err := gp.GetHeadRef(ctx)
 	if err != nil {
@@ -27,7 +31,8 @@ func NewLocalProjectState(ctx context.Context, gp GitProvider, tr TagReader) (Ex
 		return nil, errors.Errorf("getting branch: %w", err)
 	}
 
-	tags, err := tr.TagsFromBranch(ctx, branch)
+	// the working copy might be on a branch that has not been pushed yet
+	tags, err := tr.TagsFromBranch(ctx, "HEAD")
 	if err != nil {
 		return nil, errors.Errorf("getting tags from branch: %w", err)
 	}
@@ -37,12 +42,80 @@ func NewLocalProjectState(ctx context.Context, gp GitProvider, tr TagReader) (Ex
 		return nil, errors.Errorf("getting dirty: %w", err)
 	}
 
-	return &LocalProjectState{
-		Commit: commit,
-		Branch: branch,
-		Tags:   tags,
-		Dirty:  dirty,
-	}, nil
+	commitTime, err := gp.CommitTime(ctx, commit)
+	if err != nil {
+		return nil, errors.Errorf("getting commit time: %w", err)
+	}
+
+	me := &LocalProjectState{
+		Commit:     commit,
+		Branch:     branch,
+		Tags:       tags,
+		Dirty:      dirty,
+		CommitTime: commitTime,
+	}
+
+	from := ""
+	if mrlt := MostRecentLiveTag(me); !mrlt.IsZero() {
+		from = tags.MappedByName()[mrlt.String()]
+	}
+
+	me.CommitsSinceTag, err = gp.CountCommits(ctx, from, commit)
+	if err != nil {
+		return nil, errors.Errorf("counting commits since last tag: %w", err)
+	}
+
+	return me, nil
+}
+
+// DevVersion calculates a version for the working copy that is never pushed, e.g. v1.4.0-dev.3+abc1234.dirty.
+// If the working copy is clean and sits exactly on a live tag, that tag is returned.
+func (me *LocalProjectState) DevVersion(ctx context.Context) (string, error) {
+	mrlt := MostRecentLiveTag(me)
+
+	if !mrlt.IsZero() && me.CommitsSinceTag == 0 && !me.Dirty {
+		return mrlt.String(), nil
+	}
+
+	nvt := GetNextValidTag(ctx, SemVer{}, me.IsTargetingRoot(), MaxLiveOrReservedTag(mrlt, MRRT{}))
+
+	build := me.Commit
+	if len(build) > 7 {
+		build = build[:7]
+	}
+
+	if me.Dirty {
+		build += ".dirty"
+	}
+
+	return fmt.Sprintf("%s-dev.%d+%s", nvt, me.CommitsSinceTag, build), nil
+}
+
+// GoVersion returns a version that go get accepts for the current commit: the live tag if the
+// commit is tagged, otherwise a pseudo-version based on the most recent live tag.
+// Uncommitted changes are not reflected, as go can only resolve commits.
+func (me *LocalProjectState) GoVersion(modulePath string) (string, error) {
+	mrlt := MostRecentLiveTag(me)
+
+	if mrlt.IsZero() {
+		return GoPseudoVersion(modulePath, "", me.CommitTime, me.Commit)
+	}
+
+	if me.CommitsSinceTag == 0 {
+		return mrlt.String(), nil
+	}
+
+	return GoPseudoVersion(modulePath, mrlt.String(), me.CommitTime, me.Commit)
+}
+
+// HeadBranch implements Execution.
+func (me *LocalProjectState) HeadBranch() string {
+	return me.Branch
+}
+
+// BaseBranch implements Execution.
+func (me *LocalProjectState) BaseBranch() string {
+	return me.Branch
 }
 
 // BaseBranchTags implements Execution.
@@ -80,6 +153,31 @@ func (me *LocalProjectState) ProvideRefs() RefProvider {
 	return &SingleRefProvider{Ref: me.Commit}
 }
 
+// PRTags implements Execution.
+func (*LocalProjectState) PRTags() Tags {
+	return []Tag{}
+}
+
+// Branches implements Execution. Local versions do not take maintenance lines into account.
+func (*LocalProjectState) Branches() []string {
+	return []string{}
+}
+
+// HeadCommitMessage implements Execution. Skip directives only apply to releases, which are never local.
+func (*LocalProjectState) HeadCommitMessage() string {
+	return ""
+}
+
+// Labels implements Execution.
+func (*LocalProjectState) Labels() []string {
+	return []string{}
+}
+
+// ChangedFiles implements Execution.
+func (*LocalProjectState) ChangedFiles() []string {
+	return []string{}
+}
+
 // RootBranchTags implements Execution.
 func (*LocalProjectState) RootBranchTags() Tags {
 	return []Tag{}
@@ -0,0 +1,78 @@
+package simver_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+)
+
+func TestLocalDevVersion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		state    *simver.LocalProjectState
+		expected string
+	}{
+		{
+			name: "commits since tag on main",
+			state: &simver.LocalProjectState{
+				Commit:          "abc1234def5678abc1234def5678abc1234def56",
+				Branch:          "main",
+				Tags:            simver.Tags{simver.Tag{Name: "v1.3.0"}},
+				CommitsSinceTag: 3,
+			},
+			expected: "v1.4.0-dev.3+abc1234",
+		},
+		{
+			name: "dirty side branch",
+			state: &simver.LocalProjectState{
+				Commit:          "abc1234def5678abc1234def5678abc1234def56",
+				Branch:          "feature",
+				Tags:            simver.Tags{simver.Tag{Name: "v1.3.0"}, simver.Tag{Name: "v1.3.1-pr2+1"}},
+				CommitsSinceTag: 1,
+				Dirty:           true,
+			},
+			expected: "v1.3.1-dev.1+abc1234.dirty",
+		},
+		{
+			name: "dirty on tag",
+			state: &simver.LocalProjectState{
+				Commit: "abc1234def5678abc1234def5678abc1234def56",
+				Branch: "main",
+				Tags:   simver.Tags{simver.Tag{Name: "v1.3.0"}},
+				Dirty:  true,
+			},
+			expected: "v1.4.0-dev.0+abc1234.dirty",
+		},
+		{
+			name: "clean on tag",
+			state: &simver.LocalProjectState{
+				Commit: "abc1234def5678abc1234def5678abc1234def56",
+				Branch: "main",
+				Tags:   simver.Tags{simver.Tag{Name: "v1.3.0"}},
+			},
+			expected: "v1.3.0",
+		},
+		{
+			name: "no tags",
+			state: &simver.LocalProjectState{
+				Commit:          "abc1234def5678abc1234def5678abc1234def56",
+				Branch:          "main",
+				CommitsSinceTag: 12,
+			},
+			expected: "v0.2.0-dev.12+abc1234",
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.state.DevVersion(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
@@ -3,9 +3,9 @@ package simver
 import (
 	"context"
 	"encoding/json"
-	"errors"
 
 	"github.com/rs/zerolog"
+	"gitlab.com/tozd/go/errors"
 )
 
 var _ Execution = &ActivePRProjectState{}
@@ -18,6 +18,10 @@ type ActivePRProjectState struct {
 	CurrentBaseCommitTags Tags
 	CurrentBaseBranchTags Tags
 	CurrentHeadBranchTags Tags
+	CurrentPRTags         Tags
+	CurrentBranches       []string
+	CurrentCommitMessage  string
+	CurrentChangedFiles   []string
 }
 
 func (e *ActivePRProjectState) ProvideRefs() RefProvider {
@@ -40,6 +44,14 @@ func (e *ActivePRProjectState) IsMerge() bool {
 	return !e.CurrentPR.IsSimulatedPush() && e.CurrentPR.Merged
 }
 
+func (e *ActivePRProjectState) HeadBranch() string {
+	return e.CurrentPR.HeadBranch
+}
+
+func (e *ActivePRProjectState) BaseBranch() string {
+	return e.CurrentPR.BaseBranch
+}
+
 func (e *ActivePRProjectState) RootBranch() string {
 	return e.CurrentPR.RootBranch
 }
@@ -56,13 +68,50 @@ func (e *ActivePRProjectState) HeadCommitTags() Tags {
 	return e.CurrentHeadCommitTags
 }
 
-func LoadExecutionFromPR(ctx context.Context, tprov TagReader, prr PRResolver) (Execution, *PRDetails, error) {
+func (e *ActivePRProjectState) PRTags() Tags {
+	return e.CurrentPRTags
+}
+
+func (e *ActivePRProjectState) Branches() []string {
+	return e.CurrentBranches
+}
+
+func (e *ActivePRProjectState) HeadCommitMessage() string {
+	return e.CurrentCommitMessage
+}
+
+func (e *ActivePRProjectState) Labels() []string {
+	return e.CurrentPR.Labels
+}
+
+func (e *ActivePRProjectState) ChangedFiles() []string {
+	return e.CurrentChangedFiles
+}
+
+func LoadExecutionFromPR(ctx context.Context, gp GitProvider, tprov TagReader, prr PRResolver, cfg *Config) (Execution, *PRDetails, error) {
+
+	format, err := cfg.TagFormat()
+	if err != nil {
+		return nil, nil, err
+	}
 
 	pr, err := prr.CurrentPR(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	// a simulated push is its own root, but a push to a channel branch has to reserve its version on the
+	// real root branch, where pull requests and pushes to root look for reservations
+	if _, ok := cfg.ChannelFor(pr.BaseBranch); ok && pr.IsSimulatedPush() && pr.BaseBranch != DefaultRootBranch {
+		root, err := gp.CommitFromRef(ctx, "origin/"+DefaultRootBranch)
+		if err != nil {
+			return nil, nil, errors.Errorf("getting the head of %s for channel %s, the checkout needs fetch-depth: 0: %w", DefaultRootBranch, pr.BaseBranch, err)
+		}
+
+		pr.RootBranch = DefaultRootBranch
+		pr.RootCommit = root
+	}
+
 	baseCommitTags, err := tprov.TagsFromCommit(ctx, pr.BaseCommit)
 	if err != nil {
 		return nil, nil, err
@@ -106,6 +155,45 @@ func LoadExecutionFromPR(ctx context.Context, tprov TagReader, prr PRResolver) (
 		return nil, nil, err
 	}
 
+	// tags are looked up by name here (not by branch reachability) so that build tags
+	// orphaned by a force push are still taken into account
+	var prTags Tags
+	if !pr.IsSimulatedPush() {
+		for _, pattern := range format.PRPatterns(pr.Number) {
+			tags, err := tprov.TagsFromPattern(ctx, pattern)
+			if err != nil {
+				return nil, nil, err
+			}
+			prTags = append(prTags, tags...)
+		}
+	}
+
+	// the branches tell us which maintenance lines exist, whose tags are not ours to continue from
+	branches, err := gp.Branches(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// skip directives only apply to releases, where the head commit is checked out
+	var commitMessage string
+	if pr.IsSimulatedPush() || pr.Merged {
+		commitMessage, err = gp.CommitMessage(ctx, headCommit)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// open prs need their changed files too, since the version they reserve is the one they merge with.
+	// without them (like in a shallow clone) the change counts as usual.
+	var changedFiles []string
+	if len(cfg.PathGlobs()) > 0 {
+		changedFiles, err = gp.ChangedFiles(ctx, pr.BaseCommit, headCommit)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Str("base", pr.BaseCommit).Str("head", headCommit).Msg("unable to get changed files, counting every change")
+			changedFiles = nil
+		}
+	}
+
 	// beforeNoRoot := len(baseCommitTags)
 
 	// baseNoRoot := slices.DeleteFunc(baseCommitTags, func(t Tag) bool {
@@ -140,6 +228,10 @@ func LoadExecutionFromPR(ctx context.Context, tprov TagReader, prr PRResolver) (
 		CurrentBaseCommitTags: baseCommitTags,
 		CurrentRootBranchTags: rootBranchTags,
 		CurrentRootCommitTags: rootCommitTags,
+		CurrentPRTags:         prTags,
+		CurrentBranches:       branches,
+		CurrentCommitMessage:  commitMessage,
+		CurrentChangedFiles:   changedFiles,
 	}
 
 	zerolog.Ctx(ctx).Debug().
@@ -149,6 +241,10 @@ func LoadExecutionFromPR(ctx context.Context, tprov TagReader, prr PRResolver) (
 		Array("CurrentBaseCommitTags", ex.CurrentBaseCommitTags).
 		Array("CurrentBaseBranchTags", ex.CurrentBaseBranchTags).
 		Array("CurrentHeadBranchTags", ex.CurrentHeadBranchTags).
+		Array("CurrentPRTags", ex.CurrentPRTags).
+		Strs("CurrentBranches", ex.CurrentBranches).
+		Strs("CurrentChangedFiles", ex.CurrentChangedFiles).
+		Strs("Labels", ex.CurrentPR.Labels).
 		Bool("IsTargetingRoot", ex.IsTargetingRoot()).
 		Msg("loaded tags")
 
@@ -0,0 +1,48 @@
+package simver
+
+import (
+	"time"
+
+	"gitlab.com/tozd/go/errors"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// GoPseudoVersion derives a go pseudo-version (e.g. v1.2.4-0.20240102150405-abcdefabcdef) for an untagged commit
+// that comes after the release base (empty if there is none). If modulePath is not empty, the result is also checked against it, which
+// catches a major version that does not match the module path (e.g. v2 tags on a module without a /v2 suffix).
+func GoPseudoVersion(modulePath string, base string, t time.Time, commit string) (string, error) {
+	if len(commit) < 12 {
+		return "", errors.Errorf("commit hash %q is too short for a pseudo-version", commit)
+	}
+
+	rev := commit[:12]
+
+	if base != "" && !semver.IsValid(base) {
+		return "", errors.Errorf("invalid base version %q", base)
+	}
+
+	v := module.PseudoVersion(semver.Major(base), base, t, rev)
+
+	if !module.IsPseudoVersion(v) {
+		return "", errors.Errorf("generated version %q is not a valid pseudo-version", v)
+	}
+
+	gotRev, err := module.PseudoVersionRev(v)
+	if err != nil {
+		return "", errors.Errorf("reading pseudo-version revision: %w", err)
+	}
+
+	if gotRev != rev {
+		return "", errors.Errorf("pseudo-version %q does not reference commit %q", v, rev)
+	}
+
+	if modulePath != "" {
+		err = module.Check(modulePath, v)
+		if err != nil {
+			return "", errors.Errorf("checking pseudo-version against module path: %w", err)
+		}
+	}
+
+	return v, nil
+}
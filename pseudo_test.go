@@ -0,0 +1,68 @@
+package simver_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+)
+
+func TestGoPseudoVersion(t *testing.T) {
+	commitTime := time.Date(2024, 5, 17, 13, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		modulePath string
+		base       string
+		commit     string
+		expected   string
+		err        bool
+	}{
+		{
+			name:     "after live tag",
+			base:     "v1.2.3",
+			commit:   "abcdefabcdef0123456789abcdefabcdef012345",
+			expected: "v1.2.4-0.20240517130405-abcdefabcdef",
+		},
+		{
+			name:     "no live tag",
+			base:     "",
+			commit:   "abcdefabcdef0123456789abcdefabcdef012345",
+			expected: "v0.0.0-20240517130405-abcdefabcdef",
+		},
+		{
+			name:       "major version matches module path",
+			modulePath: "github.com/acme/widget/v2",
+			base:       "v2.0.1",
+			commit:     "abcdefabcdef0123456789abcdefabcdef012345",
+			expected:   "v2.0.2-0.20240517130405-abcdefabcdef",
+		},
+		{
+			name:       "major version does not match module path",
+			modulePath: "github.com/acme/widget",
+			base:       "v2.0.1",
+			commit:     "abcdefabcdef0123456789abcdefabcdef012345",
+			err:        true,
+		},
+		{
+			name:   "short commit",
+			base:   "v1.2.3",
+			commit: "abcdef",
+			err:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := simver.GoPseudoVersion(tc.modulePath, tc.base, commitTime, tc.commit)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package simver
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"gitlab.com/tozd/go/errors"
+)
+
+// Release is a GitHub release for a tag that was just created
+type Release struct {
+	Tag        string
+	Commit     string
+	Name       string
+	Notes      string
+	Draft      bool
+	Prerelease bool
+}
+
+type ReleaseWriter interface {
+	// CreateRelease creates the release, a release that already exists for the tag is left as is
+	CreateRelease(ctx context.Context, release *Release) error
+}
+
+// PlanReleases picks the new tags that get a release: versions released on the root branch and, if
+// configured, pr and channel prereleases. Build, base and reserved tags never get one.
+func PlanReleases(ex Execution, out *CalculationOutput, refs RefProvider, cfg *Config) ([]*Release, error) {
+	if cfg == nil || !cfg.Releases.Enabled {
+		return nil, nil
+	}
+
+	format, err := cfg.TagFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	releases := []*Release{}
+
+	add := func(tags []string, ref string) {
+		for _, tag := range tags {
+			role := format.RoleOf(tag)
+
+			var prerelease bool
+			switch {
+			case role == TagRoleRelease && ReleasesOnRoot(ex, cfg):
+				prerelease = false
+			case role == TagRolePR && cfg.Releases.Prereleases:
+				prerelease = true
+			case role == TagRoleChannel && cfg.Releases.Prereleases:
+				prerelease = true
+			default:
+				continue
+			}
+
+			releases = append(releases, &Release{
+				Tag:        tag,
+				Commit:     ref,
+				Name:       tag,
+				Draft:      cfg.Releases.Draft,
+				Prerelease: prerelease,
+			})
+		}
+	}
+
+	add(out.HeadTags, refs.Head())
+	add(out.MergeTags, refs.Merge())
+
+	return releases, nil
+}
+
+// CreateReleases writes the notes of each release, a changelog since the previous release, and creates it.
+// The tags of the releases have to exist already.
+func CreateReleases(ctx context.Context, gp GitProvider, prp PRProvider, tr TagReader, rw ReleaseWriter, cfg *Config, releases []*Release) error {
+	if len(releases) == 0 {
+		return nil
+	}
+
+	tags, err := tr.TagsFromPattern(ctx, "*")
+	if err != nil {
+		return errors.Errorf("listing tags: %w", err)
+	}
+
+	for _, rel := range releases {
+		cl, err := BuildChangelog(ctx, gp, prp, cfg, PreviousRelease(tags, rel.Tag), rel.Tag)
+		if err != nil {
+			return errors.Errorf("building notes for %s: %w", rel.Tag, err)
+		}
+
+		rel.Notes = cl.Markdown()
+
+		err = rw.CreateRelease(ctx, rel)
+		if err != nil {
+			return errors.Errorf("creating release %s: %w", rel.Tag, err)
+		}
+
+		zerolog.Ctx(ctx).Info().Str("tag", rel.Tag).Bool("prerelease", rel.Prerelease).Bool("draft", rel.Draft).Msg("created release")
+	}
+
+	return nil
+}
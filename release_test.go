@@ -0,0 +1,116 @@
+package simver_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+	"github.com/walteh/simver/gen/mockery"
+)
+
+func TestPlanReleases(t *testing.T) {
+	refs := &simver.BasicRefProvider{HeadRef: "head_ref", MergeRef: "merge_ref"}
+
+	testCases := []struct {
+		name            string
+		releases        simver.ReleasesConfig
+		isTargetingRoot bool
+		baseBranch      string
+		out             *simver.CalculationOutput
+		expected        []*simver.Release
+	}{
+		{
+			name:            "disabled",
+			releases:        simver.ReleasesConfig{},
+			isTargetingRoot: true,
+			out:             &simver.CalculationOutput{MergeTags: []string{"v1.3.0"}},
+			expected:        nil,
+		},
+		{
+			name:            "merge to root",
+			releases:        simver.ReleasesConfig{Enabled: true},
+			isTargetingRoot: true,
+			out:             &simver.CalculationOutput{MergeTags: []string{"v1.3.0"}},
+			expected: []*simver.Release{
+				{Tag: "v1.3.0", Commit: "merge_ref", Name: "v1.3.0"},
+			},
+		},
+		{
+			name:            "push to root with build tag",
+			releases:        simver.ReleasesConfig{Enabled: true, Draft: true},
+			isTargetingRoot: true,
+			out:             &simver.CalculationOutput{HeadTags: []string{"v1.3.0", "v1.3.0+build.4"}},
+			expected: []*simver.Release{
+				{Tag: "v1.3.0", Commit: "head_ref", Name: "v1.3.0", Draft: true},
+			},
+		},
+		{
+			name:            "merge to side branch",
+			releases:        simver.ReleasesConfig{Enabled: true},
+			isTargetingRoot: false,
+			out:             &simver.CalculationOutput{MergeTags: []string{"v1.3.1"}},
+			expected:        []*simver.Release{},
+		},
+		{
+			name:            "push to maintenance line",
+			releases:        simver.ReleasesConfig{Enabled: true},
+			isTargetingRoot: true,
+			baseBranch:      "release/1.4",
+			out:             &simver.CalculationOutput{HeadTags: []string{"v1.4.3"}},
+			expected:        []*simver.Release{},
+		},
+		{
+			name:            "pr build without prereleases",
+			releases:        simver.ReleasesConfig{Enabled: true},
+			isTargetingRoot: true,
+			out: &simver.CalculationOutput{
+				HeadTags: []string{"v1.3.0-pr4+2"},
+				BaseTags: []string{"v1.3.0-pr4+base"},
+				RootTags: []string{"v1.3.0-reserved"},
+			},
+			expected: []*simver.Release{},
+		},
+		{
+			name:            "pr build with prereleases",
+			releases:        simver.ReleasesConfig{Enabled: true, Prereleases: true},
+			isTargetingRoot: true,
+			out: &simver.CalculationOutput{
+				HeadTags: []string{"v1.3.0-pr4+2"},
+				BaseTags: []string{"v1.3.0-pr4+base"},
+				RootTags: []string{"v1.3.0-reserved"},
+			},
+			expected: []*simver.Release{
+				{Tag: "v1.3.0-pr4+2", Commit: "head_ref", Name: "v1.3.0-pr4+2", Prerelease: true},
+			},
+		},
+		{
+			name:            "channel merge with prereleases",
+			releases:        simver.ReleasesConfig{Enabled: true, Prereleases: true},
+			isTargetingRoot: false,
+			out:             &simver.CalculationOutput{MergeTags: []string{"v2.0.0-rc.3"}},
+			expected: []*simver.Release{
+				{Tag: "v2.0.0-rc.3", Commit: "merge_ref", Name: "v2.0.0-rc.3", Prerelease: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockExec := new(mockery.MockExecution_simver)
+			mockExec.EXPECT().IsTargetingRoot().Return(tc.isTargetingRoot)
+			if tc.baseBranch == "" {
+				tc.baseBranch = "main"
+			}
+			mockExec.EXPECT().BaseBranch().Return(tc.baseBranch)
+
+			cfg := simver.DefaultConfig()
+			cfg.Releases = tc.releases
+			cfg.Lines = []*simver.LineConfig{{Branch: "release/*"}}
+
+			got, err := simver.PlanReleases(mockExec, tc.out, refs, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
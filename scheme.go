@@ -0,0 +1,97 @@
+package simver
+
+import (
+	"time"
+)
+
+// Bump is the kind of change a version is released for
+type Bump string
+
+const (
+	PatchBump Bump = "patch"
+	MinorBump Bump = "minor"
+	MajorBump Bump = "major"
+)
+
+// bumpOrder ranks the kinds of change from smallest to biggest
+var bumpOrder = map[Bump]int{PatchBump: 1, MinorBump: 2, MajorBump: 3}
+
+// bumpBetween returns the kind of change that leads from one release to another
+func bumpBetween(from, to Version) Bump {
+	switch {
+	case to.Major != from.Major:
+		return MajorBump
+	case to.Minor != from.Minor:
+		return MinorBump
+	default:
+		return PatchBump
+	}
+}
+
+// Scheme decides how versions advance. Everything else (reservations, pr builds, merges) works the same for every scheme.
+type Scheme interface {
+	// Next returns the version after max for the given kind of change
+	Next(max Version, bump Bump) Version
+	// Patch returns the version right after v, used when a version is taken and the next free one is needed
+	Patch(v Version) Version
+}
+
+var (
+	_ Scheme = SemVer{}
+	_ Scheme = CalVer{}
+)
+
+// SemVer bumps the part of the version that matches the kind of change
+type SemVer struct{}
+
+func (SemVer) Next(max Version, bump Bump) Version {
+	switch bump {
+	case MajorBump:
+		return max.BumpMajor()
+	case MinorBump:
+		return max.BumpMinor()
+	default:
+		return max.BumpPatch()
+	}
+}
+
+func (SemVer) Patch(v Version) Version {
+	return v.BumpPatch()
+}
+
+// CalVer versions as vYYYY.M.N, where N counts the releases in that month.
+// Months are not zero padded, since semver does not allow leading zeros.
+type CalVer struct {
+	Now func() time.Time // defaults to time.Now
+}
+
+func (me CalVer) now() time.Time {
+	if me.Now == nil {
+		return time.Now().UTC()
+	}
+	return me.Now().UTC()
+}
+
+// Next starts over at N=0 in a new month, otherwise it counts up, whatever the kind of change.
+// Root and side branches both count up, reservations keep them from colliding. A max from a later
+// month (clock skew, or a tag created by hand) keeps counting up too, versions never go backwards.
+func (me CalVer) Next(max Version, _ Bump) Version {
+	now := me.now()
+
+	if now.Year() > max.Major || (now.Year() == max.Major && int(now.Month()) > max.Minor) {
+		return Version{Prefix: max.Prefix, Major: now.Year(), Minor: int(now.Month()), Role: TagRoleRelease}
+	}
+
+	return max.BumpPatch()
+}
+
+func (CalVer) Patch(v Version) Version {
+	return v.BumpPatch()
+}
+
+func schemeOrDefault(s Scheme) Scheme {
+	if s == nil {
+		return SemVer{}
+	}
+	return s
+}
@@ -0,0 +1,132 @@
+package simver_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+)
+
+func TestCalVerNextValidTag(t *testing.T) {
+	calver := simver.CalVer{Now: func() time.Time {
+		return time.Date(2024, time.May, 17, 13, 4, 5, 0, time.UTC)
+	}}
+
+	testCases := []struct {
+		name     string
+		max      simver.MAXLR
+		minor    bool
+		expected simver.NVT
+	}{
+		{name: "no tags", max: ver(""), minor: true, expected: ver("v2024.5.0")},
+		{name: "older month", max: ver("v2024.4.7"), minor: true, expected: ver("v2024.5.0")},
+		{name: "older year", max: ver("v2023.5.2"), minor: true, expected: ver("v2024.5.0")},
+		{name: "same month", max: ver("v2024.5.0"), minor: true, expected: ver("v2024.5.1")},
+		{name: "same month side branch", max: ver("v2024.5.3"), minor: false, expected: ver("v2024.5.4")},
+		{name: "semver tags before switching", max: ver("v1.9.0"), minor: true, expected: ver("v2024.5.0")},
+		{name: "later month", max: ver("v2024.6.2"), minor: true, expected: ver("v2024.6.3")},
+		{name: "later year", max: ver("v2025.1.0"), minor: true, expected: ver("v2025.1.1")},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := simver.GetNextValidTag(ctx, calver, tc.minor, tc.max)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestCalVerCalculateNewTags(t *testing.T) {
+	calver := simver.CalVer{Now: func() time.Time {
+		return time.Date(2024, time.May, 17, 13, 4, 5, 0, time.UTC)
+	}}
+
+	testCases := []struct {
+		name        string
+		calculation *simver.Calculation
+		output      *simver.CalculationOutput
+	}{
+		{
+			name: "new pr reserves the next calver",
+			calculation: &simver.Calculation{
+				MostRecentLiveTag: ver("v2024.5.2"),
+				PR:                4,
+				NextValidTag:      ver("v2024.5.3"),
+				Scheme:            calver,
+			},
+			output: &simver.CalculationOutput{
+				BaseTags:  []string{"v2024.5.3-pr4+base"},
+				HeadTags:  []string{"v2024.5.3-pr4+1"},
+				RootTags:  []string{"v2024.5.3-reserved"},
+				MergeTags: []string{},
+			},
+		},
+		{
+			name: "next build keeps its calver",
+			calculation: &simver.Calculation{
+				MostRecentLiveTag: ver("v2024.5.2"),
+				MyMostRecentTag:   ver("v2024.5.3"),
+				MyMostRecentBuild: 1,
+				PR:                4,
+				NextValidTag:      ver("v2024.5.4"),
+				Scheme:            calver,
+			},
+			output: &simver.CalculationOutput{
+				BaseTags:  []string{},
+				HeadTags:  []string{"v2024.5.3-pr4+2"},
+				RootTags:  []string{},
+				MergeTags: []string{},
+			},
+		},
+		{
+			name: "taken calver moves to the next one",
+			calculation: &simver.Calculation{
+				MostRecentLiveTag: ver("v2024.5.3"),
+				MyMostRecentTag:   ver("v2024.5.3"),
+				MyMostRecentBuild: 2,
+				PR:                4,
+				NextValidTag:      ver("v2024.5.5"),
+				Scheme:            calver,
+			},
+			output: &simver.CalculationOutput{
+				BaseTags:  []string{"v2024.5.4-pr4+base"},
+				HeadTags:  []string{"v2024.5.4-pr4+3"},
+				RootTags:  []string{"v2024.5.4-reserved"},
+				MergeTags: []string{},
+			},
+		},
+		{
+			name: "merge",
+			calculation: &simver.Calculation{
+				MostRecentLiveTag: ver("v2024.5.2"),
+				MyMostRecentTag:   ver("v2024.5.3"),
+				MyMostRecentBuild: 2,
+				PR:                4,
+				NextValidTag:      ver("v2024.5.4"),
+				IsMerged:          true,
+				Scheme:            calver,
+			},
+			output: &simver.CalculationOutput{
+				BaseTags:  []string{},
+				HeadTags:  []string{},
+				RootTags:  []string{},
+				MergeTags: []string{"v2024.5.3"},
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := tc.calculation.CalculateNewTagsRaw(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tc.output, out)
+		})
+	}
+}
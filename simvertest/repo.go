@@ -0,0 +1,599 @@
+// Package simvertest provides an in-memory git repository for end-to-end tests of simver. A Repo models
+// commits, branches, merges, tags and pull requests, and implements every provider interface that
+// LoadExecutionFromPR and the tag writers need, so tests can run the real calculation against realistic
+// branch reachability instead of a mocked Execution.
+package simvertest
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/walteh/simver"
+	"gitlab.com/tozd/go/errors"
+)
+
+var (
+	_ simver.GitProvider = (*Repo)(nil)
+	_ simver.TagReader   = (*Repo)(nil)
+	_ simver.TagWriter   = (*Repo)(nil)
+	_ simver.PRProvider  = (*Repo)(nil)
+	_ simver.PRResolver  = (*Repo)(nil)
+)
+
+type commit struct {
+	hash    string
+	message string
+	parents []string
+	files   []string
+	time    time.Time
+}
+
+// PR is a pull request of the fake repository
+type PR struct {
+	Number      int
+	Title       string
+	HeadBranch  string
+	BaseBranch  string
+	Labels      []string
+	Merged      bool
+	MergeCommit string
+}
+
+// event is what the current workflow run was triggered by, like GITHUB_REF and GITHUB_EVENT_NAME
+type event struct {
+	pr     int    // 0 for pushes
+	branch string // pushed branch
+	parent string // commit the branch was at before the push
+}
+
+type Repo struct {
+	Org  string
+	Repo string
+	Root string // root branch, main by default
+
+	commits  map[string]*commit
+	branches map[string]string // name -> head commit
+	tags     map[string]string // name -> commit
+	tagOrder []string
+	prs      []*PR
+
+	current event
+	clock   time.Time
+}
+
+// NewRepo returns a repository with a single commit on the root branch, which is the current push
+func NewRepo() *Repo {
+	r := &Repo{
+		Org:      "acme",
+		Repo:     "widget",
+		Root:     "main",
+		commits:  map[string]*commit{},
+		branches: map[string]string{},
+		tags:     map[string]string{},
+		clock:    time.Date(2024, 5, 17, 13, 4, 5, 0, time.UTC),
+	}
+
+	head := r.commit("initial commit", nil, nil)
+	r.branches[r.Root] = head
+	r.current = event{branch: r.Root}
+
+	return r
+}
+
+func (r *Repo) commit(message string, parents []string, files []string) string {
+	r.clock = r.clock.Add(time.Minute)
+
+	sum := sha1.Sum([]byte(fmt.Sprintf("%d\x00%s\x00%s", len(r.commits), message, strings.Join(parents, ","))))
+	hash := hex.EncodeToString(sum[:])
+
+	r.commits[hash] = &commit{hash: hash, message: message, parents: parents, files: files, time: r.clock}
+
+	return hash
+}
+
+// CreateBranch creates a branch at the head of from
+func (r *Repo) CreateBranch(name, from string) {
+	r.branches[name] = r.mustResolve(from)
+}
+
+// Commit adds a commit to branch, touching files, without changing the current event
+func (r *Repo) Commit(branch, message string, files ...string) string {
+	head := r.mustResolve(branch)
+	hash := r.commit(message, []string{head}, files)
+	r.branches[branch] = hash
+	return hash
+}
+
+// Push adds a commit to branch and makes it the current event, like a direct push
+func (r *Repo) Push(branch, message string, files ...string) string {
+	parent := r.mustResolve(branch)
+	hash := r.Commit(branch, message, files...)
+	r.current = event{branch: branch, parent: parent}
+	return hash
+}
+
+// OpenPR opens a pull request from head into base and makes it the current event
+func (r *Repo) OpenPR(head, base, title string, labels ...string) int {
+	pr := &PR{Number: len(r.prs) + 1, Title: title, HeadBranch: head, BaseBranch: base, Labels: labels}
+	r.prs = append(r.prs, pr)
+	r.current = event{pr: pr.Number}
+	return pr.Number
+}
+
+// PushToPR adds a commit to the head branch of a pull request and makes the pull request the current event
+func (r *Repo) PushToPR(number int, message string, files ...string) string {
+	pr := r.mustPR(number)
+	hash := r.Commit(pr.HeadBranch, message, files...)
+	r.current = event{pr: number}
+	return hash
+}
+
+// Label replaces the labels of a pull request
+func (r *Repo) Label(number int, labels ...string) {
+	r.mustPR(number).Labels = labels
+}
+
+// Merge merges a pull request with a merge commit and makes the merge the current event
+func (r *Repo) Merge(number int) string {
+	pr := r.mustPR(number)
+
+	base, head := r.mustResolve(pr.BaseBranch), r.mustResolve(pr.HeadBranch)
+
+	hash := r.commit(fmt.Sprintf("Merge pull request #%d from %s\n\n%s", number, pr.HeadBranch, pr.Title), []string{base, head}, nil)
+	r.branches[pr.BaseBranch] = hash
+
+	pr.Merged = true
+	pr.MergeCommit = hash
+	r.current = event{pr: number}
+
+	return hash
+}
+
+// UsePR makes a pull request the current event, e.g. to rerun simver for it
+func (r *Repo) UsePR(number int) {
+	r.mustPR(number)
+	r.current = event{pr: number}
+}
+
+// Tags returns every tag, in the order they were created
+func (r *Repo) Tags() simver.Tags {
+	tags := make(simver.Tags, 0, len(r.tagOrder))
+	for _, name := range r.tagOrder {
+		tags = append(tags, simver.Tag{Name: name, Ref: r.tags[name]})
+	}
+	return tags
+}
+
+// Simver runs the same steps as gha-simver for the current event, creating the new tags in the repository
+func (r *Repo) Simver(ctx context.Context, cfg *simver.Config) (simver.Tags, error) {
+	if cfg == nil {
+		cfg = simver.DefaultConfig()
+	}
+
+	ex, _, err := simver.LoadExecutionFromPR(ctx, r, r, r, cfg)
+	if err != nil {
+		return nil, errors.Errorf("loading execution: %w", err)
+	}
+
+	calc, err := simver.Calculate(ctx, ex, cfg)
+	if err != nil {
+		return nil, errors.Errorf("calculating: %w", err)
+	}
+
+	out, err := calc.CalculateNewTagsRaw(ctx)
+	if err != nil {
+		return nil, errors.Errorf("calculating new tags: %w", err)
+	}
+
+	tags := out.ApplyRefs(ex.ProvideRefs())
+
+	err = r.CreateTags(ctx, tags...)
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *Repo) mustPR(number int) *PR {
+	if number < 1 || number > len(r.prs) {
+		panic(fmt.Sprintf("simvertest: no pr #%d", number))
+	}
+	return r.prs[number-1]
+}
+
+func (r *Repo) mustResolve(ref string) string {
+	hash, err := r.resolve(ref)
+	if err != nil {
+		panic(fmt.Sprintf("simvertest: %v", err))
+	}
+	return hash
+}
+
+// resolve turns HEAD, a branch, a tag or a commit hash into a commit hash
+func (r *Repo) resolve(ref string) (string, error) {
+	if ref == "HEAD" {
+		return r.head(), nil
+	}
+
+	ref = strings.TrimPrefix(ref, "origin/")
+
+	if hash, ok := r.branches[ref]; ok {
+		return hash, nil
+	}
+
+	if hash, ok := r.tags[ref]; ok {
+		return hash, nil
+	}
+
+	if _, ok := r.commits[ref]; ok {
+		return ref, nil
+	}
+
+	return "", errors.Errorf("unknown ref %q", ref)
+}
+
+// head is the commit the current event checks out
+func (r *Repo) head() string {
+	if r.current.pr == 0 {
+		return r.branches[r.current.branch]
+	}
+
+	pr := r.mustPR(r.current.pr)
+	if pr.Merged {
+		return pr.MergeCommit
+	}
+
+	return r.branches[pr.HeadBranch]
+}
+
+// ancestors returns every commit reachable from hash, including itself
+func (r *Repo) ancestors(hash string) map[string]bool {
+	seen := map[string]bool{}
+	stack := []string{hash}
+
+	for len(stack) > 0 {
+		h := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if h == "" || seen[h] {
+			continue
+		}
+		seen[h] = true
+
+		if c, ok := r.commits[h]; ok {
+			stack = append(stack, c.parents...)
+		}
+	}
+
+	return seen
+}
+
+// between returns the commits reachable from to but not from from, newest first
+func (r *Repo) between(from, to string) ([]*commit, error) {
+	toHash, err := r.resolve(to)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude := map[string]bool{}
+	if from != "" {
+		fromHash, err := r.resolve(from)
+		if err != nil {
+			return nil, err
+		}
+		exclude = r.ancestors(fromHash)
+	}
+
+	out := []*commit{}
+	for h := range r.ancestors(toHash) {
+		if !exclude[h] {
+			out = append(out, r.commits[h])
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].time.After(out[j].time) })
+
+	return out, nil
+}
+
+func (r *Repo) prDetails(pr *PR) *simver.PRDetails {
+	dets := &simver.PRDetails{
+		Number:     pr.Number,
+		Title:      pr.Title,
+		HeadBranch: pr.HeadBranch,
+		BaseBranch: pr.BaseBranch,
+		RootBranch: r.Root,
+		Merged:     pr.Merged,
+		HeadCommit: r.branches[pr.HeadBranch],
+		Labels:     slices.Clone(pr.Labels),
+		RootCommit: r.branches[r.Root],
+	}
+
+	if pr.Merged {
+		dets.MergeCommit = pr.MergeCommit
+		dets.BaseCommit = r.commits[pr.MergeCommit].parents[0]
+	} else {
+		dets.BaseCommit = r.branches[pr.BaseBranch]
+	}
+
+	return dets
+}
+
+// CurrentPR implements simver.PRResolver.
+func (r *Repo) CurrentPR(_ context.Context) (*simver.PRDetails, error) {
+	if r.current.pr != 0 {
+		return r.prDetails(r.mustPR(r.current.pr)), nil
+	}
+
+	head := r.branches[r.current.branch]
+
+	parent := r.current.parent
+	if parent == "" {
+		parent = head
+	}
+
+	return simver.NewPushSimulatedPRDetails(parent, head, r.current.branch), nil
+}
+
+// PRDetailsByPRNumber implements simver.PRProvider.
+func (r *Repo) PRDetailsByPRNumber(_ context.Context, number int) (*simver.PRDetails, bool, error) {
+	if number < 1 || number > len(r.prs) {
+		return nil, false, nil
+	}
+	return r.prDetails(r.prs[number-1]), true, nil
+}
+
+// PRDetailsByCommit implements simver.PRProvider. Like a github search, it finds the pr that a commit was part of.
+func (r *Repo) PRDetailsByCommit(_ context.Context, hash string) (*simver.PRDetails, bool, error) {
+	for _, pr := range r.prs {
+		if pr.MergeCommit == hash {
+			return r.prDetails(pr), true, nil
+		}
+
+		tip := r.branches[pr.HeadBranch]
+		if pr.Merged {
+			tip = r.commits[pr.MergeCommit].parents[1]
+		}
+
+		commits, err := r.between(r.prDetails(pr).BaseCommit, tip)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if slices.ContainsFunc(commits, func(c *commit) bool { return c.hash == hash }) {
+			return r.prDetails(pr), true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// PRDetailsByBranch implements simver.PRProvider.
+func (r *Repo) PRDetailsByBranch(_ context.Context, branch string) (*simver.PRDetails, bool, error) {
+	// merged prs first, like the gh provider
+	for _, merged := range []bool{true, false} {
+		for _, pr := range r.prs {
+			if pr.HeadBranch == branch && pr.Merged == merged {
+				return r.prDetails(pr), true, nil
+			}
+		}
+	}
+	return nil, false, nil
+}
+
+// GetHeadRef implements simver.GitProvider.
+func (r *Repo) GetHeadRef(_ context.Context) (string, error) {
+	return r.head(), nil
+}
+
+// CommitFromRef implements simver.GitProvider.
+func (r *Repo) CommitFromRef(_ context.Context, ref string) (string, error) {
+	return r.resolve(ref)
+}
+
+// Branch implements simver.GitProvider.
+func (r *Repo) Branch(_ context.Context) (string, error) {
+	if r.current.pr == 0 {
+		return r.current.branch, nil
+	}
+	return r.mustPR(r.current.pr).HeadBranch, nil
+}
+
+// RepoName implements simver.GitProvider.
+func (r *Repo) RepoName(_ context.Context) (string, string, error) {
+	return r.Org, r.Repo, nil
+}
+
+// Dirty implements simver.GitProvider.
+func (r *Repo) Dirty(_ context.Context) (bool, error) {
+	return false, nil
+}
+
+// CountCommits implements simver.GitProvider.
+func (r *Repo) CountCommits(_ context.Context, from, to string) (int, error) {
+	commits, err := r.between(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return len(commits), nil
+}
+
+// CommitTime implements simver.GitProvider.
+func (r *Repo) CommitTime(_ context.Context, ref string) (time.Time, error) {
+	hash, err := r.resolve(ref)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return r.commits[hash].time, nil
+}
+
+// Branches implements simver.GitProvider.
+func (r *Repo) Branches(_ context.Context) ([]string, error) {
+	branches := make([]string, 0, len(r.branches))
+	for name := range r.branches {
+		branches = append(branches, name)
+	}
+	sort.Strings(branches)
+	return branches, nil
+}
+
+// CommitMessage implements simver.GitProvider.
+func (r *Repo) CommitMessage(_ context.Context, ref string) (string, error) {
+	hash, err := r.resolve(ref)
+	if err != nil {
+		return "", err
+	}
+	return r.commits[hash].message, nil
+}
+
+// ChangedFiles implements simver.GitProvider.
+func (r *Repo) ChangedFiles(_ context.Context, from, to string) ([]string, error) {
+	commits, err := r.between(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for _, c := range commits {
+		for _, f := range c.files {
+			if !slices.Contains(files, f) {
+				files = append(files, f)
+			}
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// Commits implements simver.GitProvider.
+func (r *Repo) Commits(_ context.Context, from, to string) ([]simver.Commit, error) {
+	hash, err := r.resolve(to)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude := map[string]bool{}
+	if from != "" {
+		fromHash, err := r.resolve(from)
+		if err != nil {
+			return nil, err
+		}
+		exclude = r.ancestors(fromHash)
+	}
+
+	// first parent only, like git log --first-parent
+	out := []simver.Commit{}
+	for hash != "" && !exclude[hash] {
+		c := r.commits[hash]
+
+		subject, body, _ := strings.Cut(c.message, "\n")
+		out = append(out, simver.Commit{Hash: c.hash, Subject: subject, Body: strings.TrimSpace(body)})
+
+		hash = ""
+		if len(c.parents) > 0 {
+			hash = c.parents[0]
+		}
+	}
+
+	return out, nil
+}
+
+// TagsFromCommit implements simver.TagReader.
+func (r *Repo) TagsFromCommit(_ context.Context, hash string) (simver.Tags, error) {
+	if hash == "" {
+		return nil, errors.New("commit hash is required")
+	}
+
+	return r.filterTags(func(name, ref string) bool { return ref == hash }), nil
+}
+
+// TagsFromBranch implements simver.TagReader.
+func (r *Repo) TagsFromBranch(_ context.Context, branch string) (simver.Tags, error) {
+	head, err := r.resolve(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	reachable := r.ancestors(head)
+
+	return r.filterTags(func(name, ref string) bool { return reachable[ref] }), nil
+}
+
+// TagsFromPattern implements simver.TagReader. Like git tag --list, * also matches slashes.
+func (r *Repo) TagsFromPattern(_ context.Context, pattern string) (simver.Tags, error) {
+	if pattern == "" {
+		return nil, errors.New("pattern is required")
+	}
+
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, `.*`)
+	expr = strings.ReplaceAll(expr, `\?`, `.`)
+	reg := regexp.MustCompile("^" + expr + "$")
+
+	return r.filterTags(func(name, ref string) bool { return reg.MatchString(name) }), nil
+}
+
+// TagsFromRemote implements simver.TagReader.
+func (r *Repo) TagsFromRemote(_ context.Context) (simver.Tags, error) {
+	return r.Tags(), nil
+}
+
+func (r *Repo) filterTags(keep func(name, ref string) bool) simver.Tags {
+	tags := simver.Tags{}
+	for _, t := range r.Tags() {
+		if keep(t.Name, t.Ref) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// CreateTags implements simver.TagWriter. Like git tag, existing tags are not overwritten.
+func (r *Repo) CreateTags(_ context.Context, tags ...simver.Tag) error {
+	for _, t := range tags {
+		if _, ok := r.tags[t.Name]; ok {
+			return errors.Errorf("tag %q already exists", t.Name)
+		}
+
+		if _, ok := r.commits[t.Ref]; !ok {
+			return errors.Errorf("tag %q points at unknown commit %q", t.Name, t.Ref)
+		}
+	}
+
+	for _, t := range tags {
+		r.tags[t.Name] = t.Ref
+		r.tagOrder = append(r.tagOrder, t.Name)
+	}
+
+	return nil
+}
+
+// MoveTags implements simver.TagWriter.
+func (r *Repo) MoveTags(_ context.Context, tags ...simver.Tag) error {
+	for _, t := range tags {
+		if _, ok := r.commits[t.Ref]; !ok {
+			return errors.Errorf("tag %q points at unknown commit %q", t.Name, t.Ref)
+		}
+
+		if _, ok := r.tags[t.Name]; !ok {
+			r.tagOrder = append(r.tagOrder, t.Name)
+		}
+		r.tags[t.Name] = t.Ref
+	}
+
+	return nil
+}
+
+// FetchTags implements simver.TagWriter.
+func (r *Repo) FetchTags(_ context.Context) (simver.Tags, error) {
+	return r.Tags(), nil
+}
@@ -0,0 +1,147 @@
+package simvertest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+	"github.com/walteh/simver/simvertest"
+)
+
+func names(tags simver.Tags) []string {
+	out := []string{}
+	for _, t := range tags {
+		out = append(out, t.Name)
+	}
+	return out
+}
+
+func TestRepoEndToEnd(t *testing.T) {
+	ctx := context.Background()
+	r := simvertest.NewRepo()
+
+	step := func(expected ...string) simver.Tags {
+		t.Helper()
+		tags, err := r.Simver(ctx, nil)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, expected, names(tags))
+		return tags
+	}
+
+	r.Push("main", "feat: first feature")
+	step("v0.2.0")
+
+	r.CreateBranch("feature", "main")
+	pr := r.OpenPR("feature", "main", "feat: second feature")
+	head := r.PushToPR(pr, "feat: second feature")
+	tags := step("v0.3.0-pr1+1", "v0.3.0-pr1+base", "v0.3.0-reserved")
+
+	for _, tag := range tags {
+		if tag.Name == "v0.3.0-pr1+1" {
+			assert.Equal(t, head, tag.Ref)
+		} else {
+			assert.Equal(t, r.Tags()[0].Ref, tag.Ref, tag.Name)
+		}
+	}
+
+	r.PushToPR(pr, "fix: review comments")
+	step("v0.3.0-pr1+2")
+
+	merge := r.Merge(pr)
+	tags = step("v0.3.0")
+	assert.Equal(t, merge, tags[0].Ref)
+
+	r.Push("main", "chore: cleanup")
+	step("v0.4.0")
+
+	// a pr into a side branch is a patch
+	r.CreateBranch("side", "main")
+	r.CreateBranch("fix", "main")
+	fix := r.OpenPR("fix", "side", "fix: side fix")
+	r.PushToPR(fix, "fix: side fix")
+	step("v0.4.1-pr2+1", "v0.4.1-pr2+base", "v0.4.1-reserved")
+
+	// labels are read from the pr
+	r.CreateBranch("breaking", "main")
+	major := r.OpenPR("breaking", "main", "feat!: breaking", "semver:major")
+	r.PushToPR(major, "feat!: breaking")
+	step("v1.0.0-pr3+1", "v1.0.0-pr3+base", "v1.0.0-reserved")
+}
+
+func TestRepoReachability(t *testing.T) {
+	ctx := context.Background()
+	r := simvertest.NewRepo()
+
+	main := r.Push("main", "feat: a", "a.go")
+	r.CreateBranch("feature", "main")
+	pr := r.OpenPR("feature", "main", "feat: b")
+	b := r.PushToPR(pr, "feat: b", "b.go", "docs/b.md")
+
+	require.NoError(t, r.CreateTags(ctx,
+		simver.Tag{Name: "v0.1.0", Ref: main},
+		simver.Tag{Name: "v0.2.0-pr1+1", Ref: b},
+	))
+
+	assert.Error(t, r.CreateTags(ctx, simver.Tag{Name: "v0.1.0", Ref: b}), "tags are not overwritten")
+
+	onMain, err := r.TagsFromBranch(ctx, "main")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v0.1.0"}, names(onMain))
+
+	onFeature, err := r.TagsFromBranch(ctx, "feature")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v0.1.0", "v0.2.0-pr1+1"}, names(onFeature))
+
+	byPattern, err := r.TagsFromPattern(ctx, simver.DefaultTagFormat.PRPattern(1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v0.2.0-pr1+1"}, names(byPattern))
+
+	files, err := r.ChangedFiles(ctx, "main", "feature")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.go", "docs/b.md"}, files)
+
+	merge := r.Merge(pr)
+
+	commits, err := r.Commits(ctx, "v0.1.0", "main")
+	require.NoError(t, err)
+	require.Len(t, commits, 1, "first parent only")
+	assert.Equal(t, merge, commits[0].Hash)
+	assert.Equal(t, "Merge pull request #1 from feature", commits[0].Subject)
+	assert.Equal(t, "feat: b", commits[0].Body)
+
+	dets, ok, err := r.PRDetailsByCommit(ctx, b)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, 1, dets.Number)
+	assert.True(t, dets.Merged)
+	assert.Equal(t, main, dets.BaseCommit)
+
+	cur, err := r.CurrentPR(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, merge, cur.MergeCommit)
+	assert.Equal(t, merge, cur.RootCommit)
+}
+
+func TestRepoChannelRerun(t *testing.T) {
+	ctx := context.Background()
+	r := simvertest.NewRepo()
+	cfg := &simver.Config{Channels: []*simver.ChannelConfig{{Branch: "release/*", Name: "rc"}}}
+
+	r.Push("main", "feat: initial")
+	_, err := r.Simver(ctx, cfg)
+	require.NoError(t, err)
+
+	r.CreateBranch("release/1", "main")
+	r.Push("release/1", "feat: candidate")
+
+	tags, err := r.Simver(ctx, cfg)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"v0.3.0-rc.1", "v0.3.0-reserved"}, names(tags))
+
+	// a rerun of the same push finds its own tag and does nothing
+	tags, err = r.Simver(ctx, cfg)
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+}
@@ -0,0 +1,379 @@
+package simvertest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/walteh/simver"
+	"gitlab.com/tozd/go/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is a timeline of pushes and pull request events, replayed against a fresh Repo:
+//
+//	name: two prs racing
+//	steps:
+//	  - open: one                # opens a pr from branch one (or head:) into main (or base:)
+//	  - open: two
+//	  - commit: one              # pushes a commit to the pr
+//	  - merge: two
+//	    expect: [v0.3.0]         # the tags this step creates
+//	  - push: main               # a direct push
+//	expect: [...]                # every tag at the end
+//
+// Each push, open, commit and merge runs simver like the workflow would. Branch and label steps only change the repo.
+type Scenario struct {
+	Name   string    `yaml:"name"`
+	Config yaml.Node `yaml:"config"` // same as .simver.yaml
+	Steps  []*Step   `yaml:"steps"`
+	Expect *[]string `yaml:"expect"`
+}
+
+// Step is a single event, exactly one of Push, Branch, Open, Commit, Label and Merge is set
+type Step struct {
+	Push   string `yaml:"push"`   // branch to push a commit to
+	Branch string `yaml:"branch"` // branch to create, from From
+	Open   string `yaml:"open"`   // name of the pr to open, with a first commit
+	Commit string `yaml:"commit"` // name of the pr to push a commit to
+	Label  string `yaml:"label"`  // name of the pr to set Labels on
+	Merge  string `yaml:"merge"`  // name of the pr to merge
+
+	From    string   `yaml:"from"`    // branch: where the branch starts, default the root branch
+	Head    string   `yaml:"head"`    // open: head branch, default the pr name, created from base if missing
+	Base    string   `yaml:"base"`    // open: base branch, default the root branch
+	Title   string   `yaml:"title"`   // open: pr title
+	Labels  []string `yaml:"labels"`  // open and label: pr labels
+	Message string   `yaml:"message"` // push, open and commit: commit message
+	Files   []string `yaml:"files"`   // push, open and commit: changed files
+
+	Expect *[]string `yaml:"expect"` // the tags the step creates, not checked if unset
+}
+
+// StepResult is what happened in a step
+type StepResult struct {
+	Index       int
+	Description string
+	Created     simver.Tags
+	Err         error
+}
+
+type Result struct {
+	Name  string
+	Steps []*StepResult
+	Tags  simver.Tags
+
+	// Failures are expectations of the scenario that were not met
+	Failures []string
+
+	// Violations are broken invariants: releases that don't go up, pr builds that don't count up,
+	// open prs that build the same version and prs that reserve a new version without a label or base change
+	Violations []string
+}
+
+func (me *Result) OK() bool {
+	return len(me.Failures) == 0 && len(me.Violations) == 0
+}
+
+// Report renders the tag history, followed by any failures and violations
+func (me *Result) Report() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "scenario: %s\n", me.Name)
+
+	for _, s := range me.Steps {
+		fmt.Fprintf(&b, "%3d. %-40s", s.Index, s.Description)
+		if s.Err != nil {
+			fmt.Fprintf(&b, " error: %v", s.Err)
+		} else if len(s.Created) == 0 {
+			b.WriteString(" -")
+		} else {
+			b.WriteString(" " + strings.Join(tagNames(s.Created), " "))
+		}
+		b.WriteString("\n")
+	}
+
+	for _, f := range me.Failures {
+		fmt.Fprintf(&b, "FAIL: %s\n", f)
+	}
+
+	for _, v := range me.Violations {
+		fmt.Fprintf(&b, "VIOLATION: %s\n", v)
+	}
+
+	return b.String()
+}
+
+func ParseScenario(byt []byte) (*Scenario, error) {
+	var s Scenario
+	err := yaml.Unmarshal(byt, &s)
+	if err != nil {
+		return nil, errors.Errorf("parsing scenario: %w", err)
+	}
+
+	for i, step := range s.Steps {
+		set := 0
+		for _, v := range []string{step.Push, step.Branch, step.Open, step.Commit, step.Label, step.Merge} {
+			if v != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return nil, errors.Errorf("step %d: exactly one of push, branch, open, commit, label and merge is required", i+1)
+		}
+	}
+
+	return &s, nil
+}
+
+func LoadScenario(fls afero.Fs, path string) (*Scenario, error) {
+	byt, err := afero.ReadFile(fls, path)
+	if err != nil {
+		return nil, errors.Errorf("reading %s: %w", path, err)
+	}
+
+	s, err := ParseScenario(byt)
+	if err != nil {
+		return nil, errors.Errorf("%s: %w", path, err)
+	}
+
+	if s.Name == "" {
+		s.Name = path
+	}
+
+	return s, nil
+}
+
+// config decodes the config of the scenario over the defaults, like LoadConfig
+func (me *Scenario) config() (*simver.Config, error) {
+	cfg := simver.DefaultConfig()
+
+	if !me.Config.IsZero() {
+		err := me.Config.Decode(cfg)
+		if err != nil {
+			return nil, errors.Errorf("parsing config: %w", err)
+		}
+	}
+
+	err := cfg.Validate()
+	if err != nil {
+		return nil, errors.Errorf("validating config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Run replays the scenario. Errors of individual steps are part of the result, the returned error is
+// only for scenarios that can't be run at all.
+func (me *Scenario) Run(ctx context.Context) (*Result, error) {
+	cfg, err := me.config()
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := cfg.TagFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	repo := NewRepo()
+	prs := map[string]int{}
+	res := &Result{Name: me.Name}
+	seen := map[string]bool{}
+	reserved := map[int]*reservation{}
+
+	violate := func(index int, msg string) {
+		if !seen[msg] {
+			seen[msg] = true
+			res.Violations = append(res.Violations, fmt.Sprintf("step %d: %s", index, msg))
+		}
+	}
+
+	pr := func(name string) (int, error) {
+		n, ok := prs[name]
+		if !ok {
+			return 0, errors.Errorf("unknown pr %q", name)
+		}
+		return n, nil
+	}
+
+	for i, step := range me.Steps {
+		sr := &StepResult{Index: i + 1}
+		res.Steps = append(res.Steps, sr)
+
+		run := true
+		before := repo.Tags()
+
+		switch {
+		case step.Push != "":
+			sr.Description = "push to " + step.Push
+			repo.Push(step.Push, orDefault(step.Message, "push to "+step.Push), step.Files...)
+		case step.Branch != "":
+			sr.Description = "branch " + step.Branch
+			run = false
+			repo.CreateBranch(step.Branch, orDefault(step.From, repo.Root))
+		case step.Open != "":
+			head, base := orDefault(step.Head, step.Open), orDefault(step.Base, repo.Root)
+			sr.Description = fmt.Sprintf("open %s (%s -> %s)", step.Open, head, base)
+			if _, ok := repo.branches[head]; !ok {
+				repo.CreateBranch(head, base)
+			}
+			title := orDefault(step.Title, step.Open)
+			prs[step.Open] = repo.OpenPR(head, base, title, step.Labels...)
+			repo.PushToPR(prs[step.Open], orDefault(step.Message, title), step.Files...)
+		case step.Commit != "":
+			sr.Description = "commit to " + step.Commit
+			n, err := pr(step.Commit)
+			if err != nil {
+				return nil, errors.Errorf("step %d: %w", i+1, err)
+			}
+			repo.PushToPR(n, orDefault(step.Message, "update "+step.Commit), step.Files...)
+		case step.Label != "":
+			sr.Description = fmt.Sprintf("label %s %v", step.Label, step.Labels)
+			run = false
+			n, err := pr(step.Label)
+			if err != nil {
+				return nil, errors.Errorf("step %d: %w", i+1, err)
+			}
+			repo.Label(n, step.Labels...)
+		case step.Merge != "":
+			sr.Description = "merge " + step.Merge
+			n, err := pr(step.Merge)
+			if err != nil {
+				return nil, errors.Errorf("step %d: %w", i+1, err)
+			}
+			repo.Merge(n)
+		}
+
+		if run {
+			sr.Created, sr.Err = repo.Simver(ctx, cfg)
+			if sr.Err != nil {
+				res.Failures = append(res.Failures, fmt.Sprintf("step %d: %s: %v", sr.Index, sr.Description, sr.Err))
+			}
+		}
+
+		if step.Expect != nil && !sameNames(*step.Expect, tagNames(sr.Created)) {
+			res.Failures = append(res.Failures, fmt.Sprintf("step %d: %s: expected %v, got %v", sr.Index, sr.Description, *step.Expect, tagNames(sr.Created)))
+		}
+
+		for _, msg := range checkInvariants(ctx, repo, format, reserved, before, sr.Created) {
+			violate(sr.Index, msg)
+		}
+	}
+
+	res.Tags = repo.Tags()
+
+	if me.Expect != nil && !sameNames(*me.Expect, tagNames(res.Tags)) {
+		res.Failures = append(res.Failures, fmt.Sprintf("expected tags %v, got %v", *me.Expect, tagNames(res.Tags)))
+	}
+
+	return res, nil
+}
+
+// reservation is the version a pr last reserved, along with what it was reserved for
+type reservation struct {
+	version string
+	base    string
+	labels  []string
+}
+
+// checkInvariants looks at the tags a step created, against the tags from before the step and the
+// reservations of earlier steps
+func checkInvariants(ctx context.Context, repo *Repo, format *simver.TagFormat, reserved map[int]*reservation, before, created simver.Tags) []string {
+	var out []string
+
+	parse := func(name string) (simver.Version, bool) {
+		v, err := simver.ParseVersion(format.Canonical(name))
+		return v, err == nil
+	}
+
+	for _, t := range created {
+		v, ok := parse(t.Name)
+		if !ok {
+			continue
+		}
+
+		switch v.Role {
+		case simver.TagRoleRelease:
+			// a release has to be higher than every release in its history
+			history, _ := repo.TagsFromBranch(ctx, t.Ref)
+			for _, h := range history {
+				if h.Name == t.Name {
+					continue
+				}
+				if o, ok := parse(h.Name); ok && o.Role == simver.TagRoleRelease && o.Prefix == v.Prefix && o.Compare(v) >= 0 {
+					out = append(out, fmt.Sprintf("release %s is not higher than %s in its history", t.Name, h.Name))
+				}
+			}
+		case simver.TagRolePR:
+			// build numbers of a pr only count up
+			for _, b := range before {
+				if o, ok := parse(b.Name); ok && o.Role == simver.TagRolePR && o.PR == v.PR && o.BuildNumber >= v.BuildNumber {
+					out = append(out, fmt.Sprintf("build %s of pr #%d does not count up from %s", t.Name, v.PR, b.Name))
+				}
+			}
+		case simver.TagRoleBase:
+			// a pr keeps its reservation until its labels or its base change
+			if v.PR < 1 || v.PR > len(repo.prs) {
+				continue
+			}
+			dets := repo.prDetails(repo.prs[v.PR-1])
+			now := &reservation{version: v.Core().String(), base: dets.BaseCommit, labels: dets.Labels}
+			if prev, ok := reserved[v.PR]; ok && prev.version != now.version && prev.base == now.base && slices.Equal(prev.labels, now.labels) {
+				out = append(out, fmt.Sprintf("pr #%d reserves %s after %s without a label or base change", v.PR, now.version, prev.version))
+			}
+			reserved[v.PR] = now
+		}
+	}
+
+	// open prs never build the same version
+	building := map[string]int{}
+	for _, p := range repo.prs {
+		if p.Merged {
+			continue
+		}
+
+		var latest *simver.Version
+		for _, t := range repo.Tags() {
+			if v, ok := parse(t.Name); ok && v.Role == simver.TagRolePR && v.PR == p.Number && (latest == nil || v.BuildNumber > latest.BuildNumber) {
+				latest = &v
+			}
+		}
+		if latest == nil {
+			continue
+		}
+
+		core := latest.Core().String()
+		if other, ok := building[core]; ok {
+			out = append(out, fmt.Sprintf("open prs #%d and #%d both build %s", other, p.Number, core))
+			continue
+		}
+		building[core] = p.Number
+	}
+
+	return out
+}
+
+func tagNames(tags simver.Tags) []string {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		out = append(out, t.Name)
+	}
+	return out
+}
+
+func sameNames(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,112 @@
+package simvertest_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver/simvertest"
+)
+
+func TestScenarios(t *testing.T) {
+	files, err := filepath.Glob("testdata/scenarios/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			s, err := simvertest.LoadScenario(afero.NewOsFs(), file)
+			require.NoError(t, err)
+
+			res, err := s.Run(context.Background())
+			require.NoError(t, err)
+			assert.True(t, res.OK(), res.Report())
+		})
+	}
+}
+
+func TestScenarioConcurrentPRs(t *testing.T) {
+	s, err := simvertest.ParseScenario([]byte(`
+name: two prs racing for the next minor
+steps:
+  - push: main
+    message: "feat: initial"
+  - open: one
+    title: "feat: one"
+  - open: two
+    title: "feat: two"
+  - commit: one
+  - commit: one
+  - merge: two
+  - merge: one
+`))
+	require.NoError(t, err)
+
+	res, err := s.Run(context.Background())
+	require.NoError(t, err)
+
+	history := [][]string{}
+	for _, step := range res.Steps {
+		require.NoError(t, step.Err)
+		history = append(history, names(step.Created))
+	}
+
+	assert.Equal(t, [][]string{
+		{"v0.2.0"},
+		{"v0.3.0-pr1+base", "v0.3.0-pr1+1", "v0.3.0-reserved"},
+		{"v0.4.0-pr2+base", "v0.4.0-pr2+1", "v0.4.0-reserved"},
+		// the base commit carries the base marker of pr #2, which is not pr #1's reservation
+		{"v0.3.0-pr1+2"},
+		{"v0.3.0-pr1+3"},
+		{"v0.4.0"},
+		// v0.3.0 is behind the release of pr #2 now
+		{"v0.4.1"},
+	}, history)
+
+	assert.Empty(t, res.Failures)
+	assert.Empty(t, res.Violations, res.Report())
+	assert.Len(t, res.Tags, 11)
+}
+
+func TestScenarioFailures(t *testing.T) {
+	s, err := simvertest.ParseScenario([]byte(`
+name: wrong expectations
+steps:
+  - push: main
+    expect: [v1.0.0]
+  - push: main
+expect: [v0.2.0]
+`))
+	require.NoError(t, err)
+
+	res, err := s.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, res.OK())
+	assert.Equal(t, []string{
+		"step 1: push to main: expected [v1.0.0], got [v0.2.0]",
+		"expected tags [v0.2.0], got [v0.2.0 v0.3.0]",
+	}, res.Failures)
+	assert.Empty(t, res.Violations)
+}
+
+func TestParseScenarioInvalid(t *testing.T) {
+	_, err := simvertest.ParseScenario([]byte(`
+steps:
+  - push: main
+    merge: one
+`))
+	assert.ErrorContains(t, err, "step 1: exactly one of")
+
+	s, err := simvertest.ParseScenario([]byte(`
+steps:
+  - commit: unknown
+`))
+	require.NoError(t, err)
+
+	_, err = s.Run(context.Background())
+	assert.ErrorContains(t, err, `unknown pr "unknown"`)
+}
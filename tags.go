@@ -2,7 +2,9 @@ package simver
 
 import (
 	"context"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/rs/zerolog"
@@ -12,10 +14,14 @@ import (
 type TagReader interface {
 	TagsFromCommit(ctx context.Context, commitHash string) (Tags, error)
 	TagsFromBranch(ctx context.Context, branch string) (Tags, error)
+	TagsFromPattern(ctx context.Context, pattern string) (Tags, error)
+	TagsFromRemote(ctx context.Context) (Tags, error)
 }
 
 type TagWriter interface {
 	CreateTags(ctx context.Context, tag ...Tag) error
+	// MoveTags points existing tags at new refs, force pushing only those tags
+	MoveTags(ctx context.Context, tag ...Tag) error
 	FetchTags(ctx context.Context) (Tags, error)
 }
 
@@ -124,3 +130,110 @@ func (t Tags) MappedByName() map[string]string {
 
 	return m
 }
+
+type TagRole string
+
+const (
+	TagRoleRelease  TagRole = "release"  // v1.2.3
+	TagRolePR       TagRole = "pr"       // v1.2.3-pr4+5
+	TagRoleBase     TagRole = "base"     // v1.2.3-pr4+base
+	TagRoleReserved TagRole = "reserved" // v1.2.3-reserved
+	TagRoleBuild    TagRole = "build"    // v1.2.3+build.6
+	TagRoleChannel  TagRole = "channel"  // v1.2.3-rc.7
+	TagRoleOther    TagRole = "other"    // any other valid semver tag
+	TagRoleInvalid  TagRole = "invalid"  // not a semver tag
+)
+
+// RoleOf classifies a tag name (without any module prefix) by what simver uses it for, in the default tag format
+func RoleOf(name string) TagRole {
+	return DefaultTagFormat.RoleOf(name)
+}
+
+// TagQuery selects tags by module prefix, role, pr number and name pattern.
+// Zero values match everything.
+type TagQuery struct {
+	Prefix  string         // module prefix, e.g. "tools/" for "tools/v1.2.3"
+	Role    TagRole        // only match tags with this role
+	PR      int            // only match pr and base tags of this pr
+	Pattern *regexp.Regexp // matched against the full tag name
+	Format  *TagFormat     // how pr, base and reserved tags look, the default format if nil
+}
+
+func (q *TagQuery) Matches(name string) bool {
+	if !strings.HasPrefix(name, q.Prefix) {
+		return false
+	}
+
+	version := strings.TrimPrefix(name, q.Prefix)
+
+	role, pr, _ := tagFormatOrDefault(q.Format).parse(version)
+	if role == TagRoleInvalid {
+		return false
+	}
+
+	if q.Role != "" && q.Role != role {
+		return false
+	}
+
+	if q.PR != 0 && pr != q.PR {
+		return false
+	}
+
+	if q.Pattern != nil && !q.Pattern.MatchString(name) {
+		return false
+	}
+
+	return true
+}
+
+// compareVersions compares two semver strings. Unlike semver.Compare, build metadata
+// is not ignored: numeric build identifiers (like the build number in -pr4+5) are
+// compared numerically, anything else lexically, so the order is always deterministic.
+func compareVersions(a, b string) int {
+	if c := semver.Compare(a, b); c != 0 {
+		return c
+	}
+
+	ab := strings.TrimPrefix(semver.Build(a), "+")
+	bb := strings.TrimPrefix(semver.Build(b), "+")
+
+	ai, aerr := strconv.Atoi(ab)
+	bi, berr := strconv.Atoi(bb)
+	if aerr == nil && berr == nil {
+		return ai - bi
+	}
+
+	return strings.Compare(ab, bb)
+}
+
+// higherTag reports whether a is higher than b, preferring the tag without build metadata when they are
+// otherwise equal
+func higherTag(a, b string) bool {
+	if semver.Compare(a, b) == 0 {
+		if ab, bb := semver.Build(a), semver.Build(b); (ab == "") != (bb == "") {
+			return ab == ""
+		}
+	}
+
+	return compareVersions(a, b) > 0
+}
+
+// Highest returns the semver-highest tag matching the query. A tag wins over build tags of the same
+// version, so a release is picked over the push build that points at the same commit.
+func (t Tags) Highest(q *TagQuery) (Tag, bool) {
+	var best Tag
+	found := false
+
+	for _, tag := range t {
+		if !q.Matches(tag.Name) {
+			continue
+		}
+
+		if !found || higherTag(strings.TrimPrefix(tag.Name, q.Prefix), strings.TrimPrefix(best.Name, q.Prefix)) {
+			best = tag
+			found = true
+		}
+	}
+
+	return best, found
+}
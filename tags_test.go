@@ -1,6 +1,7 @@
 package simver_test
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -55,3 +56,105 @@ func TestExtractCommitRefs(t *testing.T) {
 		})
 	}
 }
+
+func TestRoleOf(t *testing.T) {
+	testCases := []struct {
+		name     string
+		tag      string
+		expected simver.TagRole
+	}{
+		{name: "release", tag: "v1.2.3", expected: simver.TagRoleRelease},
+		{name: "pr build", tag: "v1.2.3-pr4+5", expected: simver.TagRolePR},
+		{name: "pr base", tag: "v1.2.3-pr4+base", expected: simver.TagRoleBase},
+		{name: "reserved", tag: "v1.2.3-reserved", expected: simver.TagRoleReserved},
+		{name: "channel prerelease", tag: "v1.2.3-rc.1", expected: simver.TagRoleChannel},
+		{name: "other prerelease", tag: "v1.2.3-alpha", expected: simver.TagRoleOther},
+		{name: "numeric prerelease", tag: "v1.2.3-1.2", expected: simver.TagRoleOther},
+		{name: "not semver", tag: "latest", expected: simver.TagRoleInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, simver.RoleOf(tc.tag))
+		})
+	}
+}
+
+func TestTagsHighest(t *testing.T) {
+	tags := simver.Tags{
+		simver.Tag{Name: "v1.2.3"},
+		simver.Tag{Name: "v1.2.4-pr4+9"},
+		simver.Tag{Name: "v1.2.4-pr4+10"},
+		simver.Tag{Name: "v1.2.4-pr7+11"},
+		simver.Tag{Name: "v1.2.4-pr4+base"},
+		simver.Tag{Name: "v1.2.4-reserved"},
+		simver.Tag{Name: "tools/v3.0.0"},
+		simver.Tag{Name: "latest"},
+	}
+
+	testCases := []struct {
+		name     string
+		query    *simver.TagQuery
+		expected string
+		found    bool
+	}{
+		{
+			name:     "any",
+			query:    &simver.TagQuery{},
+			expected: "v1.2.4-reserved",
+			found:    true,
+		},
+		{
+			name:     "release",
+			query:    &simver.TagQuery{Role: simver.TagRoleRelease},
+			expected: "v1.2.3",
+			found:    true,
+		},
+		{
+			name:     "pr builds are ordered by build number",
+			query:    &simver.TagQuery{Role: simver.TagRolePR, PR: 4},
+			expected: "v1.2.4-pr4+10",
+			found:    true,
+		},
+		{
+			name:     "module prefix",
+			query:    &simver.TagQuery{Prefix: "tools/"},
+			expected: "tools/v3.0.0",
+			found:    true,
+		},
+		{
+			name:     "pattern",
+			query:    &simver.TagQuery{Pattern: regexp.MustCompile(`-reserved$`)},
+			expected: "v1.2.4-reserved",
+			found:    true,
+		},
+		{
+			name:  "no match",
+			query: &simver.TagQuery{Role: simver.TagRolePR, PR: 99},
+			found: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tag, ok := tags.Highest(tc.query)
+			assert.Equal(t, tc.found, ok)
+			assert.Equal(t, tc.expected, tag.Name)
+		})
+	}
+}
+
+func TestTagsHighestPrefersTagsWithoutBuild(t *testing.T) {
+	for _, tags := range []simver.Tags{
+		{simver.Tag{Name: "v1.4.0"}, simver.Tag{Name: "v1.4.0+build.11"}},
+		{simver.Tag{Name: "v1.4.0+build.11"}, simver.Tag{Name: "v1.4.0"}},
+	} {
+		tag, ok := tags.Highest(&simver.TagQuery{})
+		assert.True(t, ok)
+		assert.Equal(t, "v1.4.0", tag.Name)
+	}
+
+	tag, ok := simver.Tags{simver.Tag{Name: "v1.3.0"}, simver.Tag{Name: "v1.3.0+build.12"}, simver.Tag{Name: "v1.4.0+build.11"}}.Highest(&simver.TagQuery{})
+	assert.True(t, ok)
+	assert.Equal(t, "v1.4.0+build.11", tag.Name)
+}
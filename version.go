@@ -0,0 +1,194 @@
+package simver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gitlab.com/tozd/go/errors"
+	"golang.org/x/mod/semver"
+)
+
+var versionRegex = regexp.MustCompile(`^v(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+// Version is a parsed simver tag, e.g. tools/v1.2.3-pr4+5
+type Version struct {
+	Prefix      string   // module prefix, e.g. "tools/"
+	Major       int      // 1
+	Minor       int      // 2
+	Patch       int      // 3
+	Prerelease  []string // ["pr4"]
+	Build       []string // ["5"]
+	PR          int      // 4, only set for pr and base tags
+	BuildNumber int      // 5, only set for pr, build and channel tags
+	Role        TagRole
+}
+
+// ParseVersion parses a tag name. Unlike golang.org/x/mod/semver, shorthands like v1.2 are not accepted.
+func ParseVersion(name string) (Version, error) {
+	prefix := ""
+	rest := name
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		prefix = name[:idx+1]
+		rest = name[idx+1:]
+	}
+
+	m := versionRegex.FindStringSubmatch(rest)
+	if m == nil || !semver.IsValid(rest) {
+		return Version{}, errors.Errorf("invalid version %q", name)
+	}
+
+	v := Version{Prefix: prefix}
+
+	for i, dst := range []*int{&v.Major, &v.Minor, &v.Patch} {
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return Version{}, errors.Errorf("invalid version %q: %w", name, err)
+		}
+		*dst = n
+	}
+
+	if m[4] != "" {
+		v.Prerelease = strings.Split(m[4], ".")
+	}
+
+	if m[5] != "" {
+		v.Build = strings.Split(m[5], ".")
+	}
+
+	v.Role, v.PR, v.BuildNumber = DefaultTagFormat.parse(rest)
+
+	return v, nil
+}
+
+// String formats the version back into a tag name
+func (v Version) String() string {
+	return v.Prefix + v.semver()
+}
+
+// IsZero reports whether v is the zero Version, which calculations use for "no version"
+func (v Version) IsZero() bool {
+	return v.Prefix == "" && v.Major == 0 && v.Minor == 0 && v.Patch == 0 &&
+		len(v.Prerelease) == 0 && len(v.Build) == 0 && v.Role == ""
+}
+
+// MarshalText formats the version as its tag name, and the zero Version as an empty string
+func (v Version) MarshalText() ([]byte, error) {
+	if v.IsZero() {
+		return []byte{}, nil
+	}
+	return []byte(v.String()), nil
+}
+
+// semver formats the version without its module prefix
+func (v Version) semver() string {
+	s := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// Core strips the prerelease and build metadata, which turns any simver tag into the release it is for
+func (v Version) Core() Version {
+	return Version{
+		Prefix: v.Prefix,
+		Major:  v.Major,
+		Minor:  v.Minor,
+		Patch:  v.Patch,
+		Role:   TagRoleRelease,
+	}
+}
+
+func (v Version) BumpMajor() Version {
+	out := v.Core()
+	out.Major++
+	out.Minor = 0
+	out.Patch = 0
+	return out
+}
+
+func (v Version) BumpMinor() Version {
+	out := v.Core()
+	out.Minor++
+	out.Patch = 0
+	return out
+}
+
+func (v Version) BumpPatch() Version {
+	out := v.Core()
+	out.Patch++
+	return out
+}
+
+// Compare orders versions by semver precedence, with build metadata as a deterministic tie breaker.
+// Module prefixes are not taken into account.
+func (v Version) Compare(o Version) int {
+	return compareVersions(v.semver(), o.semver())
+}
+
+type Versions []Version
+
+// Versions parses every tag once, skipping tags that are not versions
+func (t Tags) Versions() Versions {
+	versions := make(Versions, 0, len(t))
+
+	for _, tag := range t {
+		v, err := ParseVersion(tag.Name)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	return versions
+}
+
+func (vs Versions) Filter(keep func(Version) bool) Versions {
+	out := make(Versions, 0, len(vs))
+
+	for _, v := range vs {
+		if keep(v) {
+			out = append(out, v)
+		}
+	}
+
+	return out
+}
+
+// WithPrefix keeps the versions with exactly the given module prefix
+func (vs Versions) WithPrefix(prefix string) Versions {
+	return vs.Filter(func(v Version) bool {
+		return v.Prefix == prefix
+	})
+}
+
+func (vs Versions) WithRole(roles ...TagRole) Versions {
+	return vs.Filter(func(v Version) bool {
+		for _, r := range roles {
+			if v.Role == r {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (vs Versions) Highest() (Version, bool) {
+	if len(vs) == 0 {
+		return Version{}, false
+	}
+
+	best := vs[0]
+	for _, v := range vs[1:] {
+		if v.Compare(best) > 0 {
+			best = v
+		}
+	}
+
+	return best, true
+}
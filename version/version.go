@@ -0,0 +1,105 @@
+// Package version exposes the version a binary was built as. The variables are set at build time with the
+// flags printed by simver ldflags:
+//
+//	go build -ldflags "$(simver ldflags)" ./cmd/app
+//
+// Binaries built without them fall back to the module version and vcs info embedded by the go toolchain.
+package version
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// Package is the import path the -X flags point at
+const Package = "github.com/walteh/simver/version"
+
+// set with -X, they can only be strings
+var (
+	Version string
+	Commit  string
+	PR      string
+	Build   string
+)
+
+type Info struct {
+	Version string
+	Commit  string
+	PR      int  // pull request the binary was built from, 0 if none
+	Build   int  // build number of the pr or push, 0 if none
+	Dirty   bool // built from a working copy with uncommitted changes, only known from the build info
+}
+
+// Get returns the version from the -X flags, or from the build info if they were not set
+func Get() Info {
+	if Version != "" {
+		return fromFlags()
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return Info{Version: "(devel)"}
+	}
+
+	return FromBuildInfo(bi)
+}
+
+func fromFlags() Info {
+	info := Info{Version: Version, Commit: Commit}
+	info.PR, _ = strconv.Atoi(PR)
+	info.Build, _ = strconv.Atoi(Build)
+	return info
+}
+
+// FromBuildInfo reads the module version and the vcs settings recorded by the go toolchain
+func FromBuildInfo(bi *debug.BuildInfo) Info {
+	info := Info{Version: bi.Main.Version}
+	if info.Version == "" {
+		info.Version = "(devel)"
+	}
+
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Commit = s.Value
+		case "vcs.modified":
+			info.Dirty = s.Value == "true"
+		}
+	}
+
+	return info
+}
+
+// String is the version, followed by the short commit when it is known, e.g. v1.2.3 (abcdef1)
+func (me Info) String() string {
+	if len(me.Commit) < 7 {
+		return me.Version
+	}
+
+	dirty := ""
+	if me.Dirty {
+		dirty = ", dirty"
+	}
+
+	return fmt.Sprintf("%s (%s%s)", me.Version, me.Commit[:7], dirty)
+}
+
+// LDFlags renders info as -X flags for the variables of pkg, which has to declare Version, Commit, PR and Build
+func LDFlags(pkg string, info Info) string {
+	flags := []string{
+		fmt.Sprintf("-X %s.Version=%s", pkg, info.Version),
+		fmt.Sprintf("-X %s.Commit=%s", pkg, info.Commit),
+	}
+
+	if info.PR != 0 {
+		flags = append(flags, fmt.Sprintf("-X %s.PR=%d", pkg, info.PR))
+	}
+
+	if info.Build != 0 {
+		flags = append(flags, fmt.Sprintf("-X %s.Build=%d", pkg, info.Build))
+	}
+
+	return strings.Join(flags, " ")
+}
@@ -0,0 +1,47 @@
+package version_test
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/walteh/simver/version"
+)
+
+func TestLDFlags(t *testing.T) {
+	assert.Equal(t,
+		"-X github.com/walteh/simver/version.Version=v1.3.0-pr4+2 -X github.com/walteh/simver/version.Commit=abc123 -X github.com/walteh/simver/version.PR=4 -X github.com/walteh/simver/version.Build=2",
+		version.LDFlags(version.Package, version.Info{Version: "v1.3.0-pr4+2", Commit: "abc123", PR: 4, Build: 2}),
+	)
+
+	assert.Equal(t,
+		"-X example.com/app/internal/build.Version=v1.3.0 -X example.com/app/internal/build.Commit=abc123",
+		version.LDFlags("example.com/app/internal/build", version.Info{Version: "v1.3.0", Commit: "abc123"}),
+	)
+}
+
+func TestGetFromFlags(t *testing.T) {
+	version.Version, version.Commit, version.PR, version.Build = "v1.3.0-pr4+2", "abcdef1234", "4", "2"
+	t.Cleanup(func() {
+		version.Version, version.Commit, version.PR, version.Build = "", "", "", ""
+	})
+
+	info := version.Get()
+	assert.Equal(t, version.Info{Version: "v1.3.0-pr4+2", Commit: "abcdef1234", PR: 4, Build: 2}, info)
+	assert.Equal(t, "v1.3.0-pr4+2 (abcdef1)", info.String())
+}
+
+func TestFromBuildInfo(t *testing.T) {
+	info := version.FromBuildInfo(&debug.BuildInfo{
+		Main: debug.Module{Path: "example.com/app", Version: "v1.2.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "abcdef1234"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	})
+	assert.Equal(t, version.Info{Version: "v1.2.0", Commit: "abcdef1234", Dirty: true}, info)
+	assert.Equal(t, "v1.2.0 (abcdef1, dirty)", info.String())
+
+	info = version.FromBuildInfo(&debug.BuildInfo{Main: debug.Module{Path: "example.com/app"}})
+	assert.Equal(t, "(devel)", info.String())
+}
@@ -0,0 +1,114 @@
+package simver_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+)
+
+func TestParseVersion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected simver.Version
+		err      bool
+	}{
+		{
+			name:     "release",
+			input:    "v1.2.3",
+			expected: simver.Version{Major: 1, Minor: 2, Patch: 3, Role: simver.TagRoleRelease},
+		},
+		{
+			name:  "pr build",
+			input: "v1.2.3-pr4+5",
+			expected: simver.Version{
+				Major: 1, Minor: 2, Patch: 3,
+				Prerelease: []string{"pr4"}, Build: []string{"5"},
+				PR: 4, BuildNumber: 5, Role: simver.TagRolePR,
+			},
+		},
+		{
+			name:  "pr base",
+			input: "v1.2.3-pr4+base",
+			expected: simver.Version{
+				Major: 1, Minor: 2, Patch: 3,
+				Prerelease: []string{"pr4"}, Build: []string{"base"},
+				PR: 4, Role: simver.TagRoleBase,
+			},
+		},
+		{
+			name:  "reserved",
+			input: "v1.2.3-reserved",
+			expected: simver.Version{
+				Major: 1, Minor: 2, Patch: 3,
+				Prerelease: []string{"reserved"}, Role: simver.TagRoleReserved,
+			},
+		},
+		{
+			name:  "module prefix",
+			input: "tools/cli/v0.4.0-rc.1",
+			expected: simver.Version{
+				Prefix: "tools/cli/", Major: 0, Minor: 4, Patch: 0,
+				Prerelease: []string{"rc", "1"}, BuildNumber: 1, Role: simver.TagRoleChannel,
+			},
+		},
+		{name: "shorthand", input: "v1.2", err: true},
+		{name: "not a number", input: "v1.2.x", err: true},
+		{name: "leading zero", input: "v1.02.3", err: true},
+		{name: "empty", input: "", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := simver.ParseVersion(tc.input)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+			assert.Equal(t, tc.input, got.String())
+		})
+	}
+}
+
+func TestVersionBumpAndCompare(t *testing.T) {
+	v, err := simver.ParseVersion("v1.2.3-pr4+5")
+	require.NoError(t, err)
+
+	assert.Equal(t, "v1.2.3", v.Core().String())
+	assert.Equal(t, "v1.2.4", v.BumpPatch().String())
+	assert.Equal(t, "v1.3.0", v.BumpMinor().String())
+	assert.Equal(t, "v2.0.0", v.BumpMajor().String())
+
+	ordered := []string{"v1.2.3-pr4+5", "v1.2.3-pr4+10", "v1.2.3-reserved", "v1.2.3", "v1.10.0"}
+	for i := 1; i < len(ordered); i++ {
+		a, err := simver.ParseVersion(ordered[i-1])
+		require.NoError(t, err)
+		b, err := simver.ParseVersion(ordered[i])
+		require.NoError(t, err)
+		assert.Less(t, a.Compare(b), 0, "%s should sort before %s", a, b)
+		assert.Greater(t, b.Compare(a), 0, "%s should sort after %s", b, a)
+	}
+}
+
+func TestTagsVersions(t *testing.T) {
+	tags := simver.Tags{
+		simver.Tag{Name: "v1.2.3"},
+		simver.Tag{Name: "latest"},
+		simver.Tag{Name: "v1.2"},
+		simver.Tag{Name: "v1.3.0-reserved"},
+	}
+
+	versions := tags.Versions()
+	require.Len(t, versions, 2)
+
+	highest, ok := versions.WithRole(simver.TagRoleRelease).Highest()
+	require.True(t, ok)
+	assert.Equal(t, "v1.2.3", highest.String())
+
+	_, ok = versions.WithPrefix("tools/").Highest()
+	assert.False(t, ok)
+}
@@ -0,0 +1,272 @@
+package simver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/afero"
+	"gitlab.com/tozd/go/errors"
+)
+
+type VersionFileType string
+
+const (
+	VersionFileNPM    VersionFileType = "npm"    // package.json
+	VersionFileCargo  VersionFileType = "cargo"  // Cargo.toml
+	VersionFilePython VersionFileType = "python" // pyproject.toml
+	VersionFileHelm   VersionFileType = "helm"   // Chart.yaml
+	VersionFileGo     VersionFileType = "go"     // a string constant in a .go file
+)
+
+// VersionFileConfig is a file that embeds the version, and is updated to the calculated version before a build
+type VersionFileConfig struct {
+	Path string          `yaml:"path"`
+	Type VersionFileType `yaml:"type"` // inferred from the file name if empty
+	Name string          `yaml:"name"` // name of the go constant, default Version
+}
+
+// FileType returns the configured type, or the one implied by the file name
+func (me *VersionFileConfig) FileType() (VersionFileType, error) {
+	if me.Type != "" {
+		switch me.Type {
+		case VersionFileNPM, VersionFileCargo, VersionFilePython, VersionFileHelm, VersionFileGo:
+			return me.Type, nil
+		default:
+			return "", errors.Errorf("invalid version file type %q for %s", me.Type, me.Path)
+		}
+	}
+
+	switch base := path.Base(me.Path); {
+	case base == "package.json":
+		return VersionFileNPM, nil
+	case base == "Cargo.toml":
+		return VersionFileCargo, nil
+	case base == "pyproject.toml":
+		return VersionFilePython, nil
+	case base == "Chart.yaml":
+		return VersionFileHelm, nil
+	case strings.HasSuffix(base, ".go"):
+		return VersionFileGo, nil
+	default:
+		return "", errors.Errorf("cannot infer the version file type of %s, set type", me.Path)
+	}
+}
+
+var (
+	tomlSectionRegex    = regexp.MustCompile(`(?m)^\s*\[\[?([^\]]+)\]\]?\s*(?:#.*)?$`)
+	tomlVersionRegex    = regexp.MustCompile(`(?m)^(\s*version\s*=\s*)("[^"]*"|'[^']*')`)
+	helmVersionRegex    = regexp.MustCompile(`(?m)^(version:[ \t]*)(\S+)`)
+	helmAppVersionRegex = regexp.MustCompile(`(?m)^(appVersion:[ \t]*)(\S+)`)
+)
+
+// WriteVersionFiles updates every configured version file in the working tree to version. Nothing is committed.
+func WriteVersionFiles(ctx context.Context, fls afero.Fs, cfg *Config, version string) error {
+	format, err := cfg.TagFormat()
+	if err != nil {
+		return err
+	}
+
+	for _, f := range cfg.VersionFiles {
+		err := writeVersionFile(fls, f, version, format)
+		if err != nil {
+			return err
+		}
+
+		zerolog.Ctx(ctx).Info().Str("path", f.Path).Str("version", version).Msg("wrote version file")
+	}
+
+	return nil
+}
+
+// WriteVersionFile updates a single version file to version, a tag in the default format
+func WriteVersionFile(fls afero.Fs, f *VersionFileConfig, version string) error {
+	return writeVersionFile(fls, f, version, DefaultTagFormat)
+}
+
+func writeVersionFile(fls afero.Fs, f *VersionFileConfig, version string, format *TagFormat) error {
+	typ, err := f.FileType()
+	if err != nil {
+		return err
+	}
+
+	byt, err := afero.ReadFile(fls, f.Path)
+	if err != nil {
+		return errors.Errorf("reading %s: %w", f.Path, err)
+	}
+
+	// module prefixes (tools/v1.2.3) only matter for tags
+	version = version[strings.LastIndex(version, "/")+1:]
+
+	// every ecosystem except go wants the version without the v
+	bare := strings.TrimPrefix(version, "v")
+
+	var out string
+	switch typ {
+	case VersionFileNPM:
+		out, err = replaceJSONVersion(string(byt), bare)
+	case VersionFileCargo:
+		out, err = replaceTOMLVersion(string(byt), bare, "package", "workspace.package")
+	case VersionFilePython:
+		var py string
+		py, err = pythonVersion(format.Canonical(version))
+		if err == nil {
+			out, err = replaceTOMLVersion(string(byt), py, "project", "tool.poetry")
+		}
+	case VersionFileHelm:
+		out, err = replaceFirst(helmVersionRegex, string(byt), `${1}`+bare)
+		if err == nil && helmAppVersionRegex.MatchString(out) {
+			out, err = replaceFirst(helmAppVersionRegex, out, `${1}"`+bare+`"`)
+		}
+	case VersionFileGo:
+		name := f.Name
+		if name == "" {
+			name = "Version"
+		}
+		reg := regexp.MustCompile(`(?m)^(\s*(?:const\s+)?` + regexp.QuoteMeta(name) + `(?:\s+string)?\s*=\s*")[^"]*(")`)
+		out, err = replaceFirst(reg, string(byt), `${1}`+version+`${2}`)
+	}
+	if err != nil {
+		return errors.Errorf("updating %s: %w", f.Path, err)
+	}
+
+	err = afero.WriteFile(fls, f.Path, []byte(out), 0o644)
+	if err != nil {
+		return errors.Errorf("writing %s: %w", f.Path, err)
+	}
+
+	return nil
+}
+
+// pythonPrereleases maps channel names to their PEP 440 pre-release spelling
+var pythonPrereleases = map[string]string{
+	"alpha": "a", "a": "a",
+	"beta": "b", "b": "b",
+	"rc": "rc", "c": "rc", "pre": "rc", "preview": "rc",
+}
+
+// pythonVersion spells a tag the PEP 440 way, which python packaging rejects semver prereleases for:
+// pr builds (v1.3.0-pr4+2) become dev releases (1.3.0.dev2+pr4), channels (v1.3.0-rc.1) pre-releases (1.3.0rc1),
+// dev versions (v1.3.0-dev.3+abc1234) dev releases (1.3.0.dev3+abc1234) and build metadata a local version.
+func pythonVersion(version string) (string, error) {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return "", err
+	}
+
+	core := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	local := strings.ReplaceAll(strings.Join(v.Build, "."), "-", ".")
+
+	switch {
+	case v.Role == TagRolePR:
+		return fmt.Sprintf("%s.dev%d+pr%d", core, v.BuildNumber, v.PR), nil
+	case v.Role == TagRoleChannel && pythonPrereleases[v.Prerelease[0]] != "":
+		core += fmt.Sprintf("%s%d", pythonPrereleases[v.Prerelease[0]], v.BuildNumber)
+	case len(v.Prerelease) == 2 && v.Prerelease[0] == "dev":
+		n, err := strconv.Atoi(v.Prerelease[1])
+		if err != nil {
+			return "", errors.Errorf("%s has no PEP 440 equivalent", version)
+		}
+		core += fmt.Sprintf(".dev%d", n)
+	case len(v.Prerelease) > 0:
+		return "", errors.Errorf("%s has no PEP 440 equivalent, only pr builds, dev versions and alpha, beta and rc channels can be written to python version files", version)
+	}
+
+	if local != "" {
+		return core + "+" + local, nil
+	}
+
+	return core, nil
+}
+
+// replaceFirst rewrites only the first match, so nested fields with the same name are left alone
+func replaceFirst(reg *regexp.Regexp, src, repl string) (string, error) {
+	loc := reg.FindStringSubmatchIndex(src)
+	if loc == nil {
+		return "", errors.New("no version field found")
+	}
+
+	dst := reg.ExpandString(nil, repl, src, loc)
+
+	return src[:loc[0]] + string(dst) + src[loc[1]:], nil
+}
+
+// replaceJSONVersion rewrites the top level version key, keeping the rest of the file as is
+func replaceJSONVersion(src, version string) (string, error) {
+	dec := json.NewDecoder(strings.NewReader(src))
+
+	depth := 0
+	key := true
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", errors.New("no version field found")
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+			key = true
+			continue
+		}
+
+		if depth != 1 {
+			continue
+		}
+
+		if key && tok == "version" {
+			start := dec.InputOffset()
+
+			val, err := dec.Token()
+			if _, ok := val.(string); err != nil || !ok {
+				return "", errors.New("version field is not a string")
+			}
+
+			end := dec.InputOffset()
+
+			// the value, along with the colon and whitespace before it
+			seg := src[start:end]
+			open, closing := strings.Index(seg, `"`), strings.LastIndex(seg, `"`)
+
+			return src[:start] + seg[:open+1] + version + seg[closing:] + src[end:], nil
+		}
+
+		key = !key
+	}
+}
+
+// replaceTOMLVersion rewrites the version key of the first of the given tables that has one
+func replaceTOMLVersion(src, version string, tables ...string) (string, error) {
+	headers := tomlSectionRegex.FindAllStringSubmatchIndex(src, -1)
+
+	for _, table := range tables {
+		for i, h := range headers {
+			if strings.TrimSpace(src[h[2]:h[3]]) != table {
+				continue
+			}
+
+			end := len(src)
+			if i+1 < len(headers) {
+				end = headers[i+1][0]
+			}
+
+			section, err := replaceFirst(tomlVersionRegex, src[h[1]:end], `${1}"`+version+`"`)
+			if err != nil {
+				continue
+			}
+
+			return src[:h[1]] + section + src[end:], nil
+		}
+	}
+
+	return "", errors.Errorf("no version field found in [%s]", strings.Join(tables, "], ["))
+}
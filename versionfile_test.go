@@ -0,0 +1,226 @@
+package simver_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/walteh/simver"
+)
+
+func TestWriteVersionFile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     *simver.VersionFileConfig
+		version  string
+		input    string
+		expected string
+	}{
+		{
+			name:    "package.json",
+			file:    &simver.VersionFileConfig{Path: "web/package.json"},
+			version: "v1.4.0",
+			input: `{
+  "name": "widget",
+  "version": "0.0.0",
+  "dependencies": {
+    "left-pad": "1.3.0"
+  },
+  "engines": { "node": ">=20" }
+}
+`,
+			expected: `{
+  "name": "widget",
+  "version": "1.4.0",
+  "dependencies": {
+    "left-pad": "1.3.0"
+  },
+  "engines": { "node": ">=20" }
+}
+`,
+		},
+		{
+			name:    "Cargo.toml",
+			file:    &simver.VersionFileConfig{Path: "Cargo.toml"},
+			version: "v1.4.0-pr3+2",
+			input: `[package]
+name = "widget"
+version = "0.1.0" # set by simver
+edition = "2021"
+
+[[bin]]
+name = "widget"
+
+[dependencies]
+serde = { version = "1.0" }
+`,
+			expected: `[package]
+name = "widget"
+version = "1.4.0-pr3+2" # set by simver
+edition = "2021"
+
+[[bin]]
+name = "widget"
+
+[dependencies]
+serde = { version = "1.0" }
+`,
+		},
+		{
+			name:    "pyproject.toml with poetry",
+			file:    &simver.VersionFileConfig{Path: "pyproject.toml"},
+			version: "v2.0.0",
+			input: `[build-system]
+requires = ["poetry-core"]
+
+[tool.poetry]
+name = "widget"
+version = '0.0.0'
+`,
+			expected: `[build-system]
+requires = ["poetry-core"]
+
+[tool.poetry]
+name = "widget"
+version = "2.0.0"
+`,
+		},
+		{
+			name:    "Chart.yaml",
+			file:    &simver.VersionFileConfig{Path: "deploy/widget/Chart.yaml"},
+			version: "tools/v1.4.0",
+			input: `apiVersion: v2
+name: widget
+version: 0.1.0
+appVersion: "0.1.0"
+dependencies:
+  - name: redis
+    version: 17.0.0
+`,
+			expected: `apiVersion: v2
+name: widget
+version: 1.4.0
+appVersion: "1.4.0"
+dependencies:
+  - name: redis
+    version: 17.0.0
+`,
+		},
+		{
+			name:    "go constant",
+			file:    &simver.VersionFileConfig{Path: "internal/version/version.go", Name: "Current"},
+			version: "v1.4.0",
+			input: `package version
+
+const (
+	Current = "dev"
+	Other   = "dev"
+)
+`,
+			expected: `package version
+
+const (
+	Current = "v1.4.0"
+	Other   = "dev"
+)
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fls := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fls, tc.file.Path, []byte(tc.input), 0o644))
+
+			err := simver.WriteVersionFile(fls, tc.file, tc.version)
+			require.NoError(t, err)
+
+			got, err := afero.ReadFile(fls, tc.file.Path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(got))
+		})
+	}
+}
+
+func TestWritePythonVersion(t *testing.T) {
+	testCases := []struct {
+		version  string
+		expected string
+		err      string
+	}{
+		{version: "v1.3.0", expected: "1.3.0"},
+		{version: "v1.3.0-pr4+2", expected: "1.3.0.dev2+pr4"},
+		{version: "v1.3.0-rc.1", expected: "1.3.0rc1"},
+		{version: "v1.3.0-beta.2", expected: "1.3.0b2"},
+		{version: "v1.3.0-dev.3+abc1234.dirty", expected: "1.3.0.dev3+abc1234.dirty"},
+		{version: "v1.3.0+build.12", expected: "1.3.0+build.12"},
+		{version: "v1.3.0-next.1", err: "v1.3.0-next.1 has no PEP 440 equivalent"},
+		{version: "v1.3.0-reserved", err: "v1.3.0-reserved has no PEP 440 equivalent"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			fls := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fls, "pyproject.toml", []byte("[project]\nversion = \"0.0.0\"\n"), 0o644))
+
+			err := simver.WriteVersionFile(fls, &simver.VersionFileConfig{Path: "pyproject.toml"}, tc.version)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			got, err := afero.ReadFile(fls, "pyproject.toml")
+			require.NoError(t, err)
+			assert.Equal(t, "[project]\nversion = \""+tc.expected+"\"\n", string(got))
+		})
+	}
+}
+
+func TestWriteVersionFileErrors(t *testing.T) {
+	fls := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fls, "Cargo.toml", []byte("[workspace]\nmembers = [\"a\"]\n"), 0o644))
+	require.NoError(t, afero.WriteFile(fls, "VERSION", []byte("0.0.0\n"), 0o644))
+
+	err := simver.WriteVersionFile(fls, &simver.VersionFileConfig{Path: "Cargo.toml"}, "v1.0.0")
+	assert.ErrorContains(t, err, "no version field found")
+
+	err = simver.WriteVersionFile(fls, &simver.VersionFileConfig{Path: "VERSION"}, "v1.0.0")
+	assert.ErrorContains(t, err, "cannot infer")
+
+	err = simver.WriteVersionFile(fls, &simver.VersionFileConfig{Path: "missing/package.json"}, "v1.0.0")
+	assert.Error(t, err)
+}
+
+func TestWriteVersionFiles(t *testing.T) {
+	fls := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fls, simver.ConfigFileName, []byte(`
+tags:
+  pr: -pr.{pr}.{build}
+version_files:
+  - path: package.json
+  - path: version.go
+  - path: pyproject.toml
+`), 0o644))
+	require.NoError(t, afero.WriteFile(fls, "package.json", []byte(`{"version": "0.0.0"}`), 0o644))
+	require.NoError(t, afero.WriteFile(fls, "pyproject.toml", []byte("[project]\nversion = \"0.0.0\"\n"), 0o644))
+	require.NoError(t, afero.WriteFile(fls, "version.go", []byte("package main\n\nconst Version string = \"dev\"\n"), 0o644))
+
+	cfg, err := simver.LoadConfig(fls)
+	require.NoError(t, err)
+
+	err = simver.WriteVersionFiles(context.Background(), fls, cfg, "v0.3.1-pr.4.2")
+	require.NoError(t, err)
+
+	pkg, _ := afero.ReadFile(fls, "package.json")
+	assert.Equal(t, `{"version": "0.3.1-pr.4.2"}`, string(pkg))
+
+	gov, _ := afero.ReadFile(fls, "version.go")
+	assert.Equal(t, "package main\n\nconst Version string = \"v0.3.1-pr.4.2\"\n", string(gov))
+
+	// custom tag formats are read through the configured format
+	py, _ := afero.ReadFile(fls, "pyproject.toml")
+	assert.Equal(t, "[project]\nversion = \"0.3.1.dev2+pr4\"\n", string(py))
+}